@@ -0,0 +1,167 @@
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// JSONSchemaProvider is implemented by types that describe their own schema, which is used instead of reflecting
+// their fields. It is called on the zero value.
+type JSONSchemaProvider interface {
+	JSONSchema() *JSONSchema
+}
+
+//nolint:gochecknoglobals
+var (
+	typeRegistryMu sync.RWMutex
+	typeRegistry   = map[reflect.Type]*JSONSchema{}
+
+	providerInterface      = reflect.TypeOf((*JSONSchemaProvider)(nil)).Elem()
+	jsonUnmarshalerType    = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	jsonMarshalerType      = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textUnmarshalerType    = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType      = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	errUnmarshalCustomType = errors.New("failed to unmarshal custom type")
+)
+
+// RegisterType overrides the schema of typ, e.g. for types of other packages such as uuid.UUID, which cannot
+// implement JSONSchemaProvider. It takes precedence over JSONSchemaProvider.
+func RegisterType(typ reflect.Type, schema *JSONSchema) {
+	typeRegistryMu.Lock()
+	defer typeRegistryMu.Unlock()
+
+	typeRegistry[typ] = schema
+}
+
+// customSchema returns a copy of the registered or provided schema of typ, so that callers can set its title.
+func customSchema(typ reflect.Type) (*JSONSchema, bool) {
+	if typ.Kind() == reflect.Pointer {
+		return nil, false
+	}
+
+	typeRegistryMu.RLock()
+	registered, found := typeRegistry[typ]
+	typeRegistryMu.RUnlock()
+
+	if !found {
+		var provider JSONSchemaProvider
+
+		switch {
+		case typ.Implements(providerInterface):
+			provider, _ = reflect.Zero(typ).Interface().(JSONSchemaProvider)
+		case reflect.PointerTo(typ).Implements(providerInterface):
+			provider, _ = reflect.New(typ).Interface().(JSONSchemaProvider)
+		case IsTextType(typ):
+			//nolint:exhaustruct
+			schema := &JSONSchema{Type: "string"}
+			if desc, found := getDescription(typ.PkgPath(), typ.Name(), ""); found {
+				schema.Description = desc
+			}
+
+			return schema, true
+		default:
+			return nil, false
+		}
+
+		registered = provider.JSONSchema()
+	}
+
+	schema := *registered
+
+	return &schema, true
+}
+
+// IsTextType reports whether typ is encoded as a string through encoding.TextMarshaler, as with uuid.UUID or
+// netip.Addr. Types that implement json.Marshaler are not, as their json takes precedence like in encoding/json, and
+// neither are types that only implement encoding.TextUnmarshaler, which are still encoded as their fields.
+func IsTextType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Interface {
+		return false
+	}
+
+	ptrType := reflect.PointerTo(typ)
+	if ptrType.Implements(jsonMarshalerType) {
+		return false
+	}
+
+	return ptrType.Implements(textMarshalerType)
+}
+
+// marshalText encodes value with its encoding.TextMarshaler, and reports whether it implements one. Pointer receivers
+// are only used when value is addressable.
+func marshalText(value reflect.Value) (string, bool, error) {
+	if value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface || value.Type().Implements(jsonMarshalerType) {
+		return "", false, nil
+	}
+
+	var marshaler encoding.TextMarshaler
+
+	switch {
+	case value.Type().Implements(textMarshalerType):
+		marshaler, _ = value.Interface().(encoding.TextMarshaler)
+	case value.CanAddr() && reflect.PointerTo(value.Type()).Implements(textMarshalerType):
+		marshaler, _ = value.Addr().Interface().(encoding.TextMarshaler)
+	default:
+		return "", false, nil
+	}
+
+	text, err := marshaler.MarshalText()
+	if err != nil {
+		return "", true, fmt.Errorf("failed to marshal %s as text: %w", value.Type(), err)
+	}
+
+	return string(text), true, nil
+}
+
+// unmarshalCustom decodes anyVal with the json.Unmarshaler or encoding.TextUnmarshaler of value, and reports
+// whether it did. Strings go through UnmarshalText when possible, so that path and query parameters work for types
+// whose json is not a string, while other values of types that are not text types are left to reflection.
+func unmarshalCustom(anyVal any, value reflect.Value) (bool, error) {
+	if value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface || !value.CanAddr() ||
+		value.Type() == reflect.TypeOf(time.Time{}) {
+		return false, nil
+	}
+
+	ptrType := reflect.PointerTo(value.Type())
+	isJSON := ptrType.Implements(jsonUnmarshalerType)
+	isText := ptrType.Implements(textUnmarshalerType)
+
+	if !isJSON && !isText {
+		return false, nil
+	}
+
+	if stringSliceVal, isStringSlice := anyVal.([]string); isStringSlice && len(stringSliceVal) == 1 {
+		anyVal = stringSliceVal[0]
+	}
+
+	stringVal, isString := anyVal.(string)
+
+	switch {
+	case isText && isString:
+		unmarshaler, _ := value.Addr().Interface().(encoding.TextUnmarshaler)
+		if err := unmarshaler.UnmarshalText([]byte(stringVal)); err != nil {
+			return true, fmt.Errorf("%w %s: %w", errUnmarshalCustomType, value.Type(), err)
+		}
+	case isJSON:
+		data, err := json.Marshal(anyVal)
+		if err != nil {
+			return true, fmt.Errorf("%w %s: %w", errUnmarshalCustomType, value.Type(), err)
+		}
+
+		unmarshaler, _ := value.Addr().Interface().(json.Unmarshaler)
+		if err := unmarshaler.UnmarshalJSON(data); err != nil {
+			return true, fmt.Errorf("%w %s: %w", errUnmarshalCustomType, value.Type(), err)
+		}
+	case !IsTextType(value.Type()):
+		return false, nil
+	default:
+		return true, fmt.Errorf("%w %s from %v: %w", errUnmarshalCustomType, value.Type(), anyVal, errReflectType)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,213 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DimmyJing/valise/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TestMoney struct {
+	cents    int64
+	currency string
+}
+
+var errTestMoney = errors.New("invalid money")
+
+func (m TestMoney) MarshalJSON() ([]byte, error) {
+	//nolint:wrapcheck
+	return json.Marshal(fmt.Sprintf("%d.%02d %s", m.cents/100, m.cents%100, m.currency))
+}
+
+func (m *TestMoney) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		//nolint:wrapcheck
+		return err
+	}
+
+	var units, cents int64
+	if _, err := fmt.Sscanf(str, "%d.%d %s", &units, &cents, &m.currency); err != nil {
+		return errTestMoney
+	}
+
+	m.cents = units*100 + cents
+
+	return nil
+}
+
+func (TestMoney) JSONSchema() *jsonschema.JSONSchema {
+	//nolint:exhaustruct
+	return &jsonschema.JSONSchema{Type: "string", Pattern: `^\d+\.\d{2} [A-Z]{3}$`}
+}
+
+type TestPayment struct {
+	Amount TestMoney    `json:"amount"`
+	Refund *TestMoney   `json:"refund"`
+	Client netip.Addr   `json:"client"`
+	Hosts  []netip.Addr `json:"hosts"`
+}
+
+func TestCustomType(t *testing.T) {
+	t.Parallel()
+
+	//nolint:exhaustruct
+	jsonschema.RegisterType(reflect.TypeOf(netip.Addr{}), &jsonschema.JSONSchema{Type: "string", Format: "ip"})
+
+	schema, err := jsonschema.AnyToSchema(reflect.TypeOf(TestPayment{})) //nolint:exhaustruct
+	require.NoError(t, err)
+
+	propertiesJSON, err := json.Marshal(schema.Properties)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"amount": {"title": "amount", "type": "string", "pattern": "^\\d+\\.\\d{2} [A-Z]{3}$"},
+		"refund": {"title": "refund", "type": ["string", "null"], "pattern": "^\\d+\\.\\d{2} [A-Z]{3}$"},
+		"client": {"title": "client", "type": "string", "format": "ip"},
+		"hosts": {"title": "hosts", "type": "array", "items": {"type": "string", "format": "ip"}}
+	}`, string(propertiesJSON))
+
+	ts, err := jsonschema.JSONSchemaToTS(schema, "export type TestPayment = ")
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(ts,
+		"  amount: string;\n  refund: string | null;\n  client: string;\n  hosts: string[];\n}"))
+
+	var payment TestPayment
+
+	err = jsonschema.AnyToValue(map[string]any{
+		"amount": "12.34 USD",
+		"refund": nil,
+		"client": "127.0.0.1",
+		"hosts":  []string{"::1"},
+	}, reflect.ValueOf(&payment).Elem())
+	require.NoError(t, err)
+	assert.Equal(t, TestMoney{cents: 1234, currency: "USD"}, payment.Amount)
+	assert.Nil(t, payment.Refund)
+	assert.Equal(t, netip.MustParseAddr("127.0.0.1"), payment.Client)
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("::1")}, payment.Hosts)
+
+	err = jsonschema.AnyToValue(map[string]any{"amount": "12 USD", "refund": nil, "client": "x", "hosts": nil},
+		reflect.ValueOf(&payment).Elem())
+
+	var decodeErr *jsonschema.DecodeError
+
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, "/amount", decodeErr.Path)
+}
+
+type TestHost struct {
+	Addr    net.IP        `json:"addr"`
+	Prefix  netip.Prefix  `json:"prefix"`
+	Timeout time.Duration `json:"timeout"`
+}
+
+func TestTextType(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, jsonschema.IsTextType(reflect.TypeOf(net.IP{})))
+	assert.False(t, jsonschema.IsTextType(reflect.TypeOf(time.Time{})))
+	assert.False(t, jsonschema.IsTextType(reflect.TypeOf(time.Duration(0))))
+	assert.False(t, jsonschema.IsTextType(reflect.TypeOf(TestMoney{}))) //nolint:exhaustruct
+
+	schema, err := jsonschema.AnyToSchema(reflect.TypeOf(TestHost{})) //nolint:exhaustruct
+	require.NoError(t, err)
+
+	propertiesJSON, err := json.Marshal(schema.Properties)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"addr": {"title": "addr", "type": "string"},
+		"prefix": {"title": "prefix", "type": "string"},
+		"timeout": {"title": "timeout", "type": "integer", "format": "int64"}
+	}`, string(propertiesJSON))
+
+	var (
+		host      TestHost
+		decodeErr *jsonschema.DecodeError
+	)
+
+	err = jsonschema.AnyToValue(map[string]any{"addr": "10.0.0.1", "prefix": "10.0.0.0/8", "timeout": "1m30s"},
+		reflect.ValueOf(&host).Elem())
+	require.NoError(t, err)
+	assert.Equal(t, net.ParseIP("10.0.0.1"), host.Addr)
+	assert.Equal(t, netip.MustParsePrefix("10.0.0.0/8"), host.Prefix)
+	assert.Equal(t, 90*time.Second, host.Timeout)
+
+	anyVal, err := jsonschema.ValueToAny(reflect.ValueOf(host))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"addr": "10.0.0.1", "prefix": "10.0.0.0/8", "timeout": int64(90 * time.Second)},
+		anyVal)
+
+	// durations are integers of nanoseconds in json, and strings are also parsed with time.ParseDuration.
+	err = jsonschema.AnyToValue(map[string]any{"addr": "10.0.0.1", "prefix": "10.0.0.0/8", "timeout": float64(1e9)},
+		reflect.ValueOf(&host).Elem())
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, host.Timeout)
+
+	err = jsonschema.AnyToValue(map[string]any{"addr": "10.0.0.1", "prefix": "10.0.0.0/8", "timeout": "2000000000"},
+		reflect.ValueOf(&host).Elem())
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, host.Timeout)
+
+	err = jsonschema.AnyToValue(map[string]any{"addr": "10.0.0.1", "prefix": "10.0.0.0/8", "timeout": "soon"},
+		reflect.ValueOf(&host).Elem())
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, "/timeout", decodeErr.Path)
+
+	err = jsonschema.AnyToValue(map[string]any{"addr": "10.0.0.1", "prefix": "10.0.0.0", "timeout": "1"},
+		reflect.ValueOf(&host).Elem())
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, "/prefix", decodeErr.Path)
+}
+
+// TestCode only implements encoding.TextUnmarshaler, so it is parsed from strings but encoded as its fields.
+type TestCode struct {
+	Prefix string `json:"prefix"`
+	Number int    `json:"number"`
+}
+
+func (c *TestCode) UnmarshalText(text []byte) error {
+	prefix, number, found := strings.Cut(string(text), "-")
+	if !found {
+		return errTestMoney
+	}
+
+	c.Prefix = prefix
+
+	_, err := fmt.Sscanf(number, "%d", &c.Number)
+
+	//nolint:wrapcheck
+	return err
+}
+
+func TestUnmarshalTextOnly(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, jsonschema.IsTextType(reflect.TypeOf(TestCode{}))) //nolint:exhaustruct
+
+	schema, err := jsonschema.AnyToSchema(reflect.TypeOf(TestCode{})) //nolint:exhaustruct
+	require.NoError(t, err)
+	assert.Equal(t, "object", schema.Type)
+
+	var code TestCode
+
+	require.NoError(t, jsonschema.AnyToValue("AB-12", reflect.ValueOf(&code).Elem()))
+	assert.Equal(t, TestCode{Prefix: "AB", Number: 12}, code)
+
+	anyVal, err := jsonschema.ValueToAny(reflect.ValueOf(code))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"prefix": "AB", "number": int64(12)}, anyVal)
+
+	var decoded TestCode
+
+	require.NoError(t, jsonschema.AnyToValue(anyVal, reflect.ValueOf(&decoded).Elem()))
+	assert.Equal(t, code, decoded)
+	assert.Empty(t, schema.Validate(code))
+}
@@ -7,18 +7,45 @@ import (
 )
 
 type JSONSchema struct {
+	Ref                  string                                      `json:"$ref,omitempty"`
 	Title                string                                      `json:"title,omitempty"`
 	Description          string                                      `json:"description,omitempty"`
 	Type                 string                                      `json:"type,omitempty"`
 	Format               string                                      `json:"format,omitempty"`
 	Enums                []string                                    `json:"enum,omitempty"`
+	Const                any                                         `json:"const,omitempty"`
+	Default              any                                         `json:"default,omitempty"`
+	Examples             []any                                       `json:"examples,omitempty"`
+	Deprecated           bool                                        `json:"deprecated,omitempty"`
+	ReadOnly             bool                                        `json:"readOnly,omitempty"`
+	WriteOnly            bool                                        `json:"writeOnly,omitempty"`
+	Minimum              *float64                                    `json:"minimum,omitempty"`
+	Maximum              *float64                                    `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64                                    `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64                                    `json:"exclusiveMaximum,omitempty"`
+	MultipleOf           *float64                                    `json:"multipleOf,omitempty"`
+	MinLength            int                                         `json:"minLength,omitempty"`
+	MaxLength            int                                         `json:"maxLength,omitempty"`
+	Pattern              string                                      `json:"pattern,omitempty"`
 	Items                *JSONSchema                                 `json:"items,omitempty"`
 	MaxItems             int                                         `json:"maxItems,omitempty"`
 	MinItems             int                                         `json:"minItems,omitempty"`
+	UniqueItems          bool                                        `json:"uniqueItems,omitempty"`
 	Properties           *orderedmap.OrderedMap[string, *JSONSchema] `json:"properties,omitempty"`
 	Required             []string                                    `json:"required,omitempty"`
 	AdditionalProperties *JSONSchema                                 `json:"additionalProperties,omitempty"`
-	boolean              *bool
+	AnyOf                []*JSONSchema                               `json:"anyOf,omitempty"`
+	OneOf                []*JSONSchema                               `json:"oneOf,omitempty"`
+	Discriminator        *Discriminator                              `json:"discriminator,omitempty"`
+	Defs                 *orderedmap.OrderedMap[string, *JSONSchema] `json:"$defs,omitempty"`
+	// Definitions holds the definitions of documents older than draft 2019-09, which only JSONSchemaToGo reads.
+	Definitions *orderedmap.OrderedMap[string, *JSONSchema] `json:"definitions,omitempty"`
+	// Nullable adds "null" to the type, which is written as a type array, or as an anyOf for a $ref.
+	Nullable bool `json:"-"`
+	// zeroMaxLength and zeroMaxItems make a MaxLength or MaxItems of 0 a bound, rather than no bound.
+	zeroMaxLength bool
+	zeroMaxItems  bool
+	boolean       *bool
 }
 
 type OpenAPIParameter struct {
@@ -29,6 +56,12 @@ type OpenAPIParameter struct {
 	Required    bool        `json:"required,omitempty"`
 }
 
+type OpenAPIHeader struct {
+	Schema      *JSONSchema `json:"schema"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+}
+
 //nolint:gochecknoglobals,exhaustruct
 var (
 	boolean         = true
@@ -36,9 +69,27 @@ var (
 	JSONSchemaFalse = JSONSchema{boolean: new(bool)}
 )
 
-func (s *JSONSchema) MarshalJSON() ([]byte, error) {
-	type Alias JSONSchema
+// SetMaxLength sets MaxLength to maximum. A MaxLength of 0 is otherwise no bound, SetMaxLength(0) makes it one.
+func (s *JSONSchema) SetMaxLength(maximum int) {
+	s.MaxLength, s.zeroMaxLength = maximum, maximum == 0
+}
+
+// SetMaxItems sets MaxItems to maximum. A MaxItems of 0 is otherwise no bound, SetMaxItems(0) makes it one.
+func (s *JSONSchema) SetMaxItems(maximum int) {
+	s.MaxItems, s.zeroMaxItems = maximum, maximum == 0
+}
 
+// maxLength returns MaxLength, and whether it is a bound.
+func (s *JSONSchema) maxLength() (int, bool) {
+	return s.MaxLength, s.MaxLength > 0 || s.zeroMaxLength
+}
+
+// maxItems returns MaxItems, and whether it is a bound.
+func (s *JSONSchema) maxItems() (int, bool) {
+	return s.MaxItems, s.MaxItems > 0 || s.zeroMaxItems
+}
+
+func (s *JSONSchema) MarshalJSON() ([]byte, error) {
 	if s.boolean != nil {
 		if *s.boolean {
 			return []byte("true"), nil
@@ -47,8 +98,84 @@ func (s *JSONSchema) MarshalJSON() ([]byte, error) {
 		}
 	}
 
+	if !s.Nullable {
+		return marshalFields(s, nil)
+	}
+
+	nonNull := *s
+	nonNull.Nullable = false
+
+	//nolint:exhaustruct
+	null := &JSONSchema{Type: "null"}
+
+	switch {
+	case s.Ref != "":
+		//nolint:exhaustruct,wrapcheck
+		return json.Marshal(&JSONSchema{
+			Title:       s.Title,
+			Description: s.Description,
+			AnyOf:       []*JSONSchema{{Ref: s.Ref}, null},
+		})
+	case s.Type == "":
+		// schemas without a type, such as unions, cannot take "null" in a type array.
+		nonNull.Title = ""
+		nonNull.Description = ""
+
+		//nolint:exhaustruct,wrapcheck
+		return json.Marshal(&JSONSchema{
+			Title:       s.Title,
+			Description: s.Description,
+			AnyOf:       []*JSONSchema{&nonNull, null},
+		})
+	default:
+		return marshalFields(&nonNull, []string{s.Type, "null"})
+	}
+}
+
+// marshalFields encodes the fields of s, with typ as its type unless it is nil. omitempty drops a MaxLength or MaxItems
+// of 0, so those that are bounds are written through fields that shadow them.
+func marshalFields(s *JSONSchema, typ []string) ([]byte, error) {
+	type Alias JSONSchema
+
+	maxLength, hasMaxLength := s.maxLength()
+	maxItems, hasMaxItems := s.maxItems()
+
+	if (maxLength > 0 || !hasMaxLength) && (maxItems > 0 || !hasMaxItems) {
+		if typ == nil {
+			//nolint:wrapcheck
+			return json.Marshal((*Alias)(s))
+		}
+
+		//nolint:wrapcheck
+		return json.Marshal(struct {
+			*Alias
+			Type []string `json:"type"`
+		}{Alias: (*Alias)(s), Type: typ})
+	}
+
+	fields := struct {
+		*Alias
+		Type      any  `json:"type,omitempty"`
+		MaxLength *int `json:"maxLength,omitempty"`
+		MaxItems  *int `json:"maxItems,omitempty"`
+	}{Alias: (*Alias)(s), Type: nil, MaxLength: nil, MaxItems: nil}
+
+	if typ != nil {
+		fields.Type = typ
+	} else if s.Type != "" {
+		fields.Type = s.Type
+	}
+
+	if hasMaxLength {
+		fields.MaxLength = &maxLength
+	}
+
+	if hasMaxItems {
+		fields.MaxItems = &maxItems
+	}
+
 	//nolint:wrapcheck
-	return json.Marshal((*Alias)(s))
+	return json.Marshal(fields)
 }
 
 func (s *JSONSchema) UnmarshalJSON(data []byte) error {
@@ -63,8 +190,40 @@ func (s *JSONSchema) UnmarshalJSON(data []byte) error {
 	case "false":
 		s.boolean = new(bool)
 	default:
-		//nolint:wrapcheck
-		return json.Unmarshal(data, (*Alias)(s))
+		var typed struct {
+			*Alias
+			Type      any  `json:"type"`
+			MaxLength *int `json:"maxLength"`
+			MaxItems  *int `json:"maxItems"`
+		}
+
+		typed.Alias = (*Alias)(s)
+
+		if err := json.Unmarshal(data, &typed); err != nil {
+			//nolint:wrapcheck
+			return err
+		}
+
+		if typed.MaxLength != nil {
+			s.SetMaxLength(*typed.MaxLength)
+		}
+
+		if typed.MaxItems != nil {
+			s.SetMaxItems(*typed.MaxItems)
+		}
+
+		switch typ := typed.Type.(type) {
+		case string:
+			s.Type = typ
+		case []any:
+			for _, elem := range typ {
+				if elemType, ok := elem.(string); ok && elemType == "null" {
+					s.Nullable = true
+				} else if ok {
+					s.Type = elemType
+				}
+			}
+		}
 	}
 
 	return nil
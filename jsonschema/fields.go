@@ -0,0 +1,252 @@
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// StructField is a field of a struct as encoding/json sees it. The fields of untagged embedded structs are promoted
+// into the struct, and Index is the full index sequence for reflect.Value.FieldByIndex.
+type StructField struct {
+	reflect.StructField
+	// JSONName is the json tag name, or the field name with a lowercase first letter.
+	JSONName string
+	// Optional is set by the omitempty and optional tag options, and makes the field not required.
+	Optional bool
+	// OmitEmpty leaves the field out of the output when it is empty.
+	OmitEmpty bool
+	// NotNull is set by the optional tag option without omitempty, which leaves a nil field out of the output
+	// instead of writing null, and rejects null in the input.
+	NotNull bool
+	// Owner is the struct type that declares the field, which differs from the outer type for promoted fields.
+	Owner reflect.Type
+}
+
+//nolint:gochecknoglobals
+var fieldCache sync.Map
+
+// StructFields returns the json fields of the struct type typ in index order, following the promotion rules of
+// encoding/json: shallower fields shadow deeper ones, and of the fields at the same depth a single tagged field
+// wins, while any other conflict leaves the name out entirely.
+func StructFields(typ reflect.Type) []StructField {
+	return cachedFields(typ, false)
+}
+
+// OutFields returns the fields of the struct type typ tagged with out, such as response headers, with the promotion
+// rules of StructFields. Out fields are usually left out of the json with "-", so those are named as if untagged.
+func OutFields(typ reflect.Type) []StructField {
+	fields := []StructField{}
+
+	for _, field := range cachedFields(typ, true) {
+		if _, found := field.Tag.Lookup("out"); found {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+type fieldCacheKey struct {
+	typ        reflect.Type
+	withOutput bool
+}
+
+func cachedFields(typ reflect.Type, withOutput bool) []StructField {
+	key := fieldCacheKey{typ: typ, withOutput: withOutput}
+
+	if cached, found := fieldCache.Load(key); found {
+		if fields, ok := cached.([]StructField); ok {
+			return fields
+		}
+	}
+
+	fields := typeFields(typ, withOutput)
+	fieldCache.Store(key, fields)
+
+	return fields
+}
+
+type fieldCandidate struct {
+	field  StructField
+	depth  int
+	tagged bool
+}
+
+// typeFields collects the json fields of typ, along with the out fields that are left out of the json if
+// withOutput is set.
+func typeFields(typ reflect.Type, withOutput bool) []StructField { //nolint:cyclop,funlen
+	candidates := []fieldCandidate{}
+	visited := map[reflect.Type]struct{}{}
+
+	next := []reflect.Type{typ}
+	nextIndex := [][]int{nil}
+
+	for depth := 0; len(next) > 0; depth++ {
+		current, currentIndex := next, nextIndex
+		next, nextIndex = nil, nil
+
+		// types of the same depth are all walked, so that the fields of a struct embedded twice cancel each other out.
+		skip := map[int]bool{}
+
+		for idx, structType := range current {
+			if _, found := visited[structType]; found {
+				skip[idx] = true
+			}
+		}
+
+		for idx, structType := range current {
+			if skip[idx] {
+				continue
+			}
+
+			visited[structType] = struct{}{}
+
+			for fieldIdx := range structType.NumField() {
+				field := structType.Field(fieldIdx)
+
+				fieldType := field.Type
+				if field.Anonymous && fieldType.Kind() == reflect.Pointer {
+					fieldType = fieldType.Elem()
+				}
+
+				if !field.IsExported() && (!field.Anonymous || fieldType.Kind() != reflect.Struct) {
+					continue
+				}
+
+				name := ""
+				optional := false
+				omitEmpty := false
+
+				if jsonTag, found := field.Tag.Lookup("json"); found {
+					splitTags := strings.Split(jsonTag, ",")
+					if splitTags[0] == "-" && len(splitTags) == 1 {
+						if _, isOutput := field.Tag.Lookup("out"); !withOutput || !isOutput {
+							continue
+						}
+					} else {
+						name = splitTags[0]
+					}
+
+					omitEmpty = slices.Contains(splitTags[1:], "omitempty")
+					optional = omitEmpty || slices.Contains(splitTags[1:], "optional")
+				}
+
+				index := append(slices.Clone(currentIndex[idx]), fieldIdx)
+
+				if name == "" && field.Anonymous && fieldType.Kind() == reflect.Struct {
+					next = append(next, fieldType)
+					nextIndex = append(nextIndex, index)
+
+					continue
+				}
+
+				if !field.IsExported() {
+					continue
+				}
+
+				notNull := optional && !omitEmpty
+
+				if isOptional(field.Type) {
+					optional = true
+					notNull = false
+				}
+
+				tagged := name != ""
+				if !tagged {
+					name = strings.ToLower(string(field.Name[0])) + field.Name[1:]
+				}
+
+				field.Index = index
+
+				candidates = append(candidates, fieldCandidate{
+					field: StructField{
+						StructField: field,
+						JSONName:    name,
+						Optional:    optional,
+						OmitEmpty:   omitEmpty,
+						NotNull:     notNull,
+						Owner:       structType,
+					},
+					depth:  depth,
+					tagged: tagged,
+				})
+			}
+		}
+	}
+
+	return dominantFields(candidates)
+}
+
+func dominantFields(candidates []fieldCandidate) []StructField {
+	byName := map[string][]fieldCandidate{}
+	names := []string{}
+
+	for _, candidate := range candidates {
+		if _, found := byName[candidate.field.JSONName]; !found {
+			names = append(names, candidate.field.JSONName)
+		}
+
+		byName[candidate.field.JSONName] = append(byName[candidate.field.JSONName], candidate)
+	}
+
+	fields := []StructField{}
+
+	for _, name := range names {
+		// candidates are appended depth by depth, so the first one has the smallest depth.
+		group := byName[name]
+		shallowest := []fieldCandidate{}
+		tagged := []fieldCandidate{}
+
+		for _, candidate := range group {
+			if candidate.depth != group[0].depth {
+				break
+			}
+
+			shallowest = append(shallowest, candidate)
+
+			if candidate.tagged {
+				tagged = append(tagged, candidate)
+			}
+		}
+
+		switch {
+		case len(shallowest) == 1:
+			fields = append(fields, shallowest[0].field)
+		case len(tagged) == 1:
+			fields = append(fields, tagged[0].field)
+		}
+	}
+
+	slices.SortFunc(fields, func(a, b StructField) int {
+		return slices.Compare(a.Index, b.Index)
+	})
+
+	return fields
+}
+
+var errEmbeddedPointer = errors.New("cannot set embedded pointer to unexported struct")
+
+// FieldByIndex returns the field of the struct value at index, allocating the nil embedded pointers on the way.
+func FieldByIndex(value reflect.Value, index []int) (reflect.Value, error) {
+	for idx, fieldIdx := range index {
+		if idx > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				if !value.CanSet() {
+					return reflect.Value{}, fmt.Errorf("%s: %w", value.Type().Elem(), errEmbeddedPointer)
+				}
+
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+
+			value = value.Elem()
+		}
+
+		value = value.Field(fieldIdx)
+	}
+
+	return value, nil
+}
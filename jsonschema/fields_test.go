@@ -0,0 +1,161 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/DimmyJing/valise/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TestPagination struct {
+	Page  int `json:"page"`
+	Limit int `json:"limit,omitempty"`
+}
+
+type TestAudit struct {
+	CreatedBy string `json:"createdBy"`
+	Page      string
+}
+
+type testEmbeddedName struct {
+	Code string
+}
+
+type TestOtherName struct {
+	Code string
+}
+
+type TestTaggedName struct {
+	Name string `json:"name"`
+	Note string `json:"note"`
+}
+
+type TestEmbedded struct {
+	TestPagination
+	*TestAudit
+	testEmbeddedName
+	TestOtherName
+	TestTaggedName `json:"tagged"`
+	ID             string `json:"id"`
+}
+
+func TestStructFields(t *testing.T) {
+	t.Parallel()
+
+	fields := jsonschema.StructFields(reflect.TypeOf(TestEmbedded{})) //nolint:exhaustruct
+
+	names := []string{}
+	for _, field := range fields {
+		names = append(names, field.JSONName)
+	}
+
+	// the tagged page wins over the untagged one at the same depth, while code is left out as it is ambiguous.
+	assert.Equal(t, []string{"page", "limit", "createdBy", "tagged", "id"}, names)
+	assert.Equal(t, []int{0, 1}, fields[1].Index)
+	assert.True(t, fields[1].Optional)
+	assert.Equal(t, reflect.TypeOf(TestAudit{}), fields[2].Owner) //nolint:exhaustruct
+
+	value := TestEmbedded{
+		TestPagination:   TestPagination{Page: 2, Limit: 10},
+		TestAudit:        nil,
+		testEmbeddedName: testEmbeddedName{Code: "hidden"},
+		TestOtherName:    TestOtherName{Code: "other"},
+		TestTaggedName:   TestTaggedName{Name: "tag", Note: "note"},
+		ID:               "id",
+	}
+
+	anyVal, err := jsonschema.ValueToAny(reflect.ValueOf(value))
+	require.NoError(t, err)
+
+	expected, err := json.Marshal(value)
+	require.NoError(t, err)
+
+	actual, err := json.Marshal(anyVal)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expected), string(actual))
+
+	var decoded TestEmbedded
+
+	err = jsonschema.AnyToValue(map[string]any{
+		"page":      2,
+		"limit":     10,
+		"createdBy": "me",
+		"tagged":    map[string]any{"name": "tag", "note": "note"},
+		"id":        "id",
+	}, reflect.ValueOf(&decoded).Elem())
+	require.NoError(t, err)
+	require.NotNil(t, decoded.TestAudit)
+	assert.Equal(t, "me", decoded.CreatedBy)
+	assert.Equal(t, 10, decoded.Limit)
+
+	schema, err := jsonschema.AnyToSchema(reflect.TypeOf(TestEmbedded{})) //nolint:exhaustruct
+	require.NoError(t, err)
+	assert.Equal(t, []string{"page", "createdBy", "tagged", "id"}, schema.Required)
+
+	_, found := schema.Properties.Get("testPagination")
+	assert.False(t, found)
+}
+
+type testShadowed struct {
+	TestPagination
+	Page string `json:"page"`
+}
+
+func TestStructFieldsShadowing(t *testing.T) {
+	t.Parallel()
+
+	fields := jsonschema.StructFields(reflect.TypeOf(testShadowed{})) //nolint:exhaustruct
+	require.Len(t, fields, 2)
+	assert.Equal(t, "limit", fields[0].JSONName)
+	assert.Equal(t, "page", fields[1].JSONName)
+	assert.Equal(t, reflect.TypeOf(""), fields[1].Type)
+
+	params, err := jsonschema.ParametersToSchema(reflect.TypeOf(testShadowed{}), true) //nolint:exhaustruct
+	require.NoError(t, err)
+	require.Len(t, params, 2)
+	assert.Equal(t, "string", params[1].Schema.Type)
+}
+
+type TestTrace struct {
+	TraceID string `json:"X-Trace-Id"  out:"header"`
+	Status  int    `json:"-"           out:"status"`
+	Span    string `json:"X-Span-Id"   out:"header"`
+}
+
+type TestMeta struct {
+	Span string `json:"X-Meta-Span" out:"header"`
+}
+
+type TestTracedOutput struct {
+	TestTrace
+	TestMeta `json:"meta"`
+	Span     string `json:"X-Span-Id,omitempty" out:"header"`
+	Body     string `json:"body"`
+}
+
+func TestOutFields(t *testing.T) {
+	t.Parallel()
+
+	outputType := reflect.TypeOf(TestTracedOutput{}) //nolint:exhaustruct
+
+	fields := jsonschema.OutFields(outputType)
+
+	names := []string{}
+	for _, field := range fields {
+		names = append(names, field.JSONName)
+	}
+
+	// the outer span shadows the embedded one, and the tagged meta is not promoted, the same as in the body.
+	assert.Equal(t, []string{"X-Trace-Id", "status", "X-Span-Id"}, names)
+	assert.Equal(t, []int{2}, fields[2].Index)
+	assert.True(t, fields[2].Optional)
+
+	headers, err := jsonschema.NewReflector("#/components/schemas/").ResponseHeadersToSchema(outputType)
+	require.NoError(t, err)
+	assert.Len(t, headers, 2)
+	assert.True(t, headers["X-Trace-Id"].Required)
+	assert.False(t, headers["X-Span-Id"].Required)
+}
@@ -0,0 +1,85 @@
+package jsonschema
+
+import (
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"sync"
+	"time"
+)
+
+//nolint:gochecknoglobals
+var (
+	uuidRegex     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnameRegex = regexp.MustCompile(`^` + hostnameLabel + `(\.` + hostnameLabel + `)*$`)
+)
+
+const hostnameLabel = `[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?`
+
+//nolint:gochecknoglobals
+var formatCheckers = map[string]func(string) bool{
+	"date-time": func(s string) bool {
+		_, err := time.Parse(time.RFC3339, s)
+
+		return err == nil
+	},
+	"date": func(s string) bool {
+		_, err := time.Parse(time.DateOnly, s)
+
+		return err == nil
+	},
+	"email": func(s string) bool {
+		addr, err := mail.ParseAddress(s)
+
+		return err == nil && addr.Address == s
+	},
+	"uuid": uuidRegex.MatchString,
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+
+		return err == nil && u.Scheme != ""
+	},
+	"ipv4": func(s string) bool {
+		addr, err := netip.ParseAddr(s)
+
+		return err == nil && addr.Is4()
+	},
+	"ipv6": func(s string) bool {
+		addr, err := netip.ParseAddr(s)
+
+		return err == nil && addr.Is6()
+	},
+	"hostname": func(s string) bool {
+		//nolint:mnd
+		return len(s) <= 253 && hostnameRegex.MatchString(s)
+	},
+}
+
+func checkFormat(format string, value string) bool {
+	if checker, found := formatCheckers[format]; found {
+		return checker(value)
+	}
+
+	return true
+}
+
+//nolint:gochecknoglobals
+var patternCache sync.Map
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if cached, found := patternCache.Load(pattern); found {
+		if re, ok := cached.(*regexp.Regexp); ok {
+			return re, nil
+		}
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	patternCache.Store(pattern, re)
+
+	return re, nil
+}
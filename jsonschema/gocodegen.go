@@ -0,0 +1,546 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// JSONSchemaToGo generates the source of a go file of package pkgName, declaring name as the type of inp along with
+// a type per entry of its $defs and definitions.
+func JSONSchemaToGo(inp *JSONSchema, pkgName string, name string) (string, error) {
+	schemas := orderedmap.New[string, *JSONSchema]()
+	schemas.Set(name, inp)
+
+	for _, defs := range []*orderedmap.OrderedMap[string, *JSONSchema]{inp.Defs, inp.Definitions} {
+		if defs == nil {
+			continue
+		}
+
+		for pair := defs.Oldest(); pair != nil; pair = pair.Next() {
+			if _, found := schemas.Get(pair.Key); found {
+				return "", fmt.Errorf("definition %s is declared twice: %w", pair.Key, errInvalidSchema)
+			}
+
+			schemas.Set(pair.Key, pair.Value)
+		}
+	}
+
+	return JSONSchemasToGo(schemas, pkgName)
+}
+
+// JSONSchemasToGo generates the source of a go file of package pkgName, declaring a type per schema, e.g. for the
+// components of an OpenAPI document. A $ref refers to the schema named by its last segment. Objects become structs,
+// string enums become types implementing EnumMember, and constraints become validate and schema tags, so that the
+// types can be used as rpc handler inputs.
+func JSONSchemasToGo(schemas *orderedmap.OrderedMap[string, *JSONSchema], pkgName string) (string, error) {
+	gen := &goGenerator{
+		names:   map[string]struct{}{},
+		refs:    map[string]string{},
+		imports: map[string]struct{}{},
+		decls:   []string{},
+	}
+
+	for pair := schemas.Oldest(); pair != nil; pair = pair.Next() {
+		gen.refs[pair.Key] = gen.newName(goIdentifier(pair.Key))
+	}
+
+	for pair := schemas.Oldest(); pair != nil; pair = pair.Next() {
+		if err := gen.declare(gen.refs[pair.Key], pair.Value); err != nil {
+			return "", fmt.Errorf("failed to convert %s to go: %w", pair.Key, err)
+		}
+	}
+
+	var source strings.Builder
+
+	source.WriteString("// Code generated by valise. DO NOT EDIT.\n\npackage " + pkgName + "\n")
+
+	if len(gen.imports) > 0 {
+		source.WriteString("\nimport (\n")
+
+		// the standard library goes first, separated from the other imports.
+		for _, standard := range []bool{true, false} {
+			imports := []string{}
+
+			for pkgPath := range gen.imports {
+				if !strings.Contains(strings.Split(pkgPath, "/")[0], ".") == standard {
+					imports = append(imports, strconv.Quote(pkgPath))
+				}
+			}
+
+			slices.Sort(imports)
+
+			if len(imports) > 0 && !standard && len(imports) != len(gen.imports) {
+				source.WriteString("\n")
+			}
+
+			for _, pkgPath := range imports {
+				source.WriteString("\t" + pkgPath + "\n")
+			}
+		}
+
+		source.WriteString(")\n")
+	}
+
+	for _, decl := range gen.decls {
+		source.WriteString("\n" + decl)
+	}
+
+	formatted, err := format.Source([]byte(source.String()))
+	if err != nil {
+		return "", fmt.Errorf("failed to format go source: %w", err)
+	}
+
+	return string(formatted), nil
+}
+
+type goGenerator struct {
+	names   map[string]struct{}
+	refs    map[string]string
+	imports map[string]struct{}
+	decls   []string
+}
+
+// newName reserves name, numbering it if it is already taken.
+func (g *goGenerator) newName(name string) string {
+	result := name
+	for idx := 2; ; idx++ {
+		if _, found := g.names[result]; !found {
+			break
+		}
+
+		result = name + strconv.Itoa(idx)
+	}
+
+	g.names[result] = struct{}{}
+
+	return result
+}
+
+// declare adds the declaration of name, before the declarations of the types nested in schema.
+func (g *goGenerator) declare(name string, schema *JSONSchema) error {
+	idx := len(g.decls)
+	g.decls = append(g.decls, "")
+
+	var decl strings.Builder
+
+	decl.WriteString(goComment(schema))
+
+	switch {
+	case schema.Type == "string" && len(schema.Enums) > 0 && !schema.Nullable:
+		g.imports["github.com/DimmyJing/valise/jsonschema"] = struct{}{}
+
+		constants := make([]string, len(schema.Enums))
+
+		fmt.Fprintf(&decl, "type %s string\n\nconst (\n", name)
+
+		for memberIdx, member := range schema.Enums {
+			constants[memberIdx] = g.newName(name + goIdentifier(member))
+			fmt.Fprintf(&decl, "\t%s %s = %s\n", constants[memberIdx], name, strconv.Quote(member))
+		}
+
+		fmt.Fprintf(&decl, ")\n\nfunc (%s) Members() []string {\n", name)
+		fmt.Fprintf(&decl, "\treturn jsonschema.EnumMembers(%s)\n}\n", strings.Join(constants, ", "))
+	case isGoStruct(schema):
+		structExpr, err := g.structType(name, schema)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(&decl, "type %s %s\n", name, structExpr)
+	default:
+		typeExpr, err := g.typeExpr(schema, name)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(&decl, "type %s %s\n", name, typeExpr)
+	}
+
+	g.decls[idx] = decl.String()
+
+	return nil
+}
+
+func isGoStruct(schema *JSONSchema) bool {
+	return !schema.Nullable && (schema.Type == "object" || schema.Type == "") &&
+		schema.Properties != nil && schema.Properties.Len() > 0
+}
+
+func (g *goGenerator) structType(name string, schema *JSONSchema) (string, error) {
+	var fields strings.Builder
+
+	fieldNames := map[string]struct{}{}
+
+	fields.WriteString("struct {\n")
+
+	for pair := schema.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		key, property := pair.Key, pair.Value
+
+		fieldName := goIdentifier(key)
+		for idx := 2; ; idx++ {
+			if _, found := fieldNames[fieldName]; !found {
+				break
+			}
+
+			fieldName = goIdentifier(key) + strconv.Itoa(idx)
+		}
+
+		fieldNames[fieldName] = struct{}{}
+
+		typeExpr, err := g.typeExpr(property, name+fieldName)
+		if err != nil {
+			return "", fmt.Errorf("failed to convert property %s: %w", key, err)
+		}
+
+		jsonTag := key
+
+		// optional properties that are not nullable are pointers with the optional option, which rejects null.
+		switch {
+		case slices.Contains(schema.Required, key):
+		case isNilable(typeExpr):
+			jsonTag += ",omitempty"
+		default:
+			typeExpr = "*" + typeExpr
+			jsonTag += ",optional"
+		}
+
+		fields.WriteString(indentComment(goComment(property)))
+		fmt.Fprintf(&fields, "\t%s %s %s\n", fieldName, typeExpr, goStructTag(jsonTag, property, typeExpr))
+	}
+
+	fields.WriteString("}")
+
+	return fields.String(), nil
+}
+
+// typeExpr converts schema to a go type expression, declaring the types of nested objects and enums as hint.
+func (g *goGenerator) typeExpr(schema *JSONSchema, hint string) (string, error) { //nolint:cyclop,funlen,gocognit
+	if schema.boolean != nil {
+		return "any", nil
+	}
+
+	if schema.Nullable {
+		nonNull := *schema
+		nonNull.Nullable = false
+
+		typeExpr, err := g.typeExpr(&nonNull, hint)
+		if err != nil {
+			return "", err
+		}
+
+		if isNilable(typeExpr) {
+			return typeExpr, nil
+		}
+
+		return "*" + typeExpr, nil
+	}
+
+	if len(schema.AnyOf) > 0 || len(schema.OneOf) > 0 {
+		members := slices.DeleteFunc(append(slices.Clone(schema.AnyOf), schema.OneOf...), func(member *JSONSchema) bool {
+			return member.Type == "null" && !member.Nullable
+		})
+
+		// unions other than nullable types have no go equivalent.
+		if len(members) != 1 {
+			return "any", nil
+		}
+
+		member := *members[0]
+		member.Nullable = member.Nullable || len(members) != len(schema.AnyOf)+len(schema.OneOf)
+
+		return g.typeExpr(&member, hint)
+	}
+
+	if schema.Ref != "" {
+		name, found := g.refs[RefName(schema.Ref)]
+		if !found {
+			return "", fmt.Errorf("unknown ref %s: %w", schema.Ref, errInvalidSchema)
+		}
+
+		return name, nil
+	}
+
+	typ := schema.Type
+	if typ == "" && schema.Const != nil {
+		switch schema.Const.(type) {
+		case string:
+			typ = "string"
+		case bool:
+			typ = "boolean"
+		case float64, json.Number:
+			typ = "number"
+		}
+	}
+
+	switch typ {
+	case "string":
+		switch {
+		case len(schema.Enums) > 0:
+			name := g.newName(hint)
+
+			return name, g.declare(name, schema)
+		case schema.Format == "date-time":
+			g.imports["time"] = struct{}{}
+
+			return "time.Time", nil
+		case schema.Format == "binary":
+			return "[]byte", nil
+		default:
+			return "string", nil
+		}
+	case "integer":
+		switch schema.Format {
+		case "int32":
+			return "int32", nil
+		case "int64":
+			return "int64", nil
+		default:
+			return "int", nil
+		}
+	case "number":
+		if schema.Format == "float" {
+			return "float32", nil
+		}
+
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		if schema.Items == nil {
+			return "[]any", nil
+		}
+
+		items, err := g.typeExpr(schema.Items, hint+"Item")
+		if err != nil {
+			return "", fmt.Errorf("failed to convert array items: %w", err)
+		}
+
+		return "[]" + items, nil
+	case "null":
+		return "any", nil
+	case "object", "":
+		if isGoStruct(schema) {
+			name := g.newName(hint)
+
+			return name, g.declare(name, schema)
+		}
+
+		if schema.AdditionalProperties != nil && schema.AdditionalProperties.boolean == nil {
+			values, err := g.typeExpr(schema.AdditionalProperties, hint+"Value")
+			if err != nil {
+				return "", fmt.Errorf("failed to convert additional properties: %w", err)
+			}
+
+			return "map[string]" + values, nil
+		}
+
+		if typ == "" {
+			return "any", nil
+		}
+
+		return "map[string]any", nil
+	}
+
+	return "", fmt.Errorf("invalid type %s: %w", schema.Type, errInvalidSchema)
+}
+
+func isNilable(typeExpr string) bool {
+	return typeExpr == "any" || strings.HasPrefix(typeExpr, "[]") || strings.HasPrefix(typeExpr, "map[") ||
+		strings.HasPrefix(typeExpr, "*")
+}
+
+// goStructTag formats the json tag of a field along with the validate and schema tags of the constraints of property.
+func goStructTag(jsonTag string, property *JSONSchema, typeExpr string) string { //nolint:cyclop
+	baseType := strings.TrimPrefix(typeExpr, "*")
+
+	validateOptions := []string{}
+	schemaOptions := []string{}
+
+	addBounds := func(minimum int, maximum int, hasMaximum bool) {
+		switch {
+		case hasMaximum && minimum == maximum:
+			validateOptions = append(validateOptions, "len="+strconv.Itoa(minimum))
+		default:
+			if minimum != 0 {
+				validateOptions = append(validateOptions, "min="+strconv.Itoa(minimum))
+			}
+
+			if hasMaximum {
+				validateOptions = append(validateOptions, "max="+strconv.Itoa(maximum))
+			}
+		}
+	}
+
+	addFloat := func(options *[]string, key string, value *float64) {
+		if value != nil {
+			*options = append(*options, key+"="+strconv.FormatFloat(*value, 'f', -1, 64))
+		}
+	}
+
+	addValue := func(key string, value any) {
+		if str, isString := value.(string); isString {
+			schemaOptions = append(schemaOptions, key+"="+str)
+		} else if encoded, err := json.Marshal(value); err == nil {
+			schemaOptions = append(schemaOptions, key+"="+string(encoded))
+		}
+	}
+
+	switch {
+	case baseType == "string" || baseType == "[]byte":
+		maxLength, hasMaxLength := property.maxLength()
+		addBounds(property.MinLength, maxLength, hasMaxLength)
+
+		if property.Pattern != "" && baseType == "string" {
+			validateOptions = append(validateOptions, "pattern="+property.Pattern)
+		}
+
+		if _, found := formatCheckers[property.Format]; found && baseType == "string" {
+			validateOptions = append(validateOptions, "format="+property.Format)
+		}
+	case strings.HasPrefix(baseType, "[]"):
+		maxItems, hasMaxItems := property.maxItems()
+		addBounds(property.MinItems, maxItems, hasMaxItems)
+
+		if property.UniqueItems {
+			schemaOptions = append(schemaOptions, "uniqueItems")
+		}
+	case strings.HasPrefix(baseType, "int") || strings.HasPrefix(baseType, "float"):
+		addFloat(&validateOptions, "min", property.Minimum)
+		addFloat(&validateOptions, "max", property.Maximum)
+		addFloat(&schemaOptions, "exclusiveMin", property.ExclusiveMinimum)
+		addFloat(&schemaOptions, "exclusiveMax", property.ExclusiveMaximum)
+		addFloat(&schemaOptions, "multipleOf", property.MultipleOf)
+	}
+
+	if property.Ref == "" && len(property.Enums) == 0 {
+		if property.Default != nil {
+			addValue("default", property.Default)
+		}
+
+		for _, example := range property.Examples {
+			addValue("example", example)
+		}
+
+		if property.Const != nil {
+			addValue("const", property.Const)
+		}
+	}
+
+	for _, flag := range []struct {
+		key string
+		set bool
+	}{{"deprecated", property.Deprecated}, {"readOnly", property.ReadOnly}, {"writeOnly", property.WriteOnly}} {
+		if flag.set {
+			schemaOptions = append(schemaOptions, flag.key)
+		}
+	}
+
+	tag := "json:" + strconv.Quote(jsonTag)
+
+	if len(validateOptions) > 0 {
+		tag += " validate:" + strconv.Quote(strings.Join(validateOptions, ","))
+	}
+
+	if len(schemaOptions) > 0 {
+		tag += " schema:" + strconv.Quote(strings.Join(schemaOptions, ","))
+	}
+
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag)
+	}
+
+	return "`" + tag + "`"
+}
+
+// goComment formats the description of schema as a go comment, along with its deprecation notice.
+func goComment(schema *JSONSchema) string {
+	lines := []string{}
+
+	if schema.Description != "" {
+		lines = append(lines, strings.Split(schema.Description, "\n")...)
+	}
+
+	if schema.Deprecated {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+
+		lines = append(lines, "Deprecated: do not use.")
+	}
+
+	var builder strings.Builder
+
+	for _, line := range lines {
+		builder.WriteString(strings.TrimRight("// "+line, " ") + "\n")
+	}
+
+	return builder.String()
+}
+
+func indentComment(comment string) string {
+	if comment == "" {
+		return ""
+	}
+
+	return "\t" + strings.ReplaceAll(strings.TrimSuffix(comment, "\n"), "\n", "\n\t") + "\n"
+}
+
+//nolint:gochecknoglobals
+var goInitialisms = []string{"api", "html", "http", "https", "id", "ip", "json", "sql", "uri", "url", "uuid", "xml"}
+
+// goIdentifier converts name to an exported go identifier, e.g. "user_id" and "userId" to "UserID".
+func goIdentifier(name string) string {
+	words := []string{}
+	word := []rune{}
+
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = []rune{}
+		}
+	}
+
+	for _, char := range name {
+		switch {
+		case !unicode.IsLetter(char) && !unicode.IsDigit(char):
+			flush()
+		case unicode.IsUpper(char) && len(word) > 0 && unicode.IsLower(word[len(word)-1]):
+			flush()
+
+			word = append(word, char)
+		default:
+			word = append(word, char)
+		}
+	}
+
+	flush()
+
+	var builder strings.Builder
+
+	for _, word := range words {
+		if slices.Contains(goInitialisms, strings.ToLower(word)) {
+			builder.WriteString(strings.ToUpper(word))
+		} else {
+			runes := []rune(word)
+			builder.WriteString(string(unicode.ToUpper(runes[0])) + string(runes[1:]))
+		}
+	}
+
+	result := builder.String()
+
+	switch {
+	case result == "":
+		return "Empty"
+	case unicode.IsDigit([]rune(result)[0]):
+		return "N" + result
+	default:
+		return result
+	}
+}
@@ -0,0 +1,118 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/DimmyJing/valise/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchemaToGo(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	var schema jsonschema.JSONSchema
+
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"description": "An order event sent by the partner.",
+		"type": "object",
+		"required": ["event_id", "type", "order", "created_at"],
+		"properties": {
+			"event_id": {"type": "string", "format": "uuid"},
+			"type": {"type": "string", "enum": ["order.created", "order.cancelled"], "description": "The kind of event."},
+			"created_at": {"type": "string", "format": "date-time"},
+			"order": {"$ref": "#/definitions/Order"},
+			"note": {"type": ["string", "null"], "maxLength": 200},
+			"retry": {"type": "integer", "minimum": 0, "maximum": 5, "default": 0},
+			"legacyCode": {"type": "string", "deprecated": true, "pattern": "^[A-Z]{3}$"},
+			"metadata": {"type": "object", "additionalProperties": {"type": "string"}}
+		},
+		"definitions": {
+			"Order": {
+				"type": "object",
+				"required": ["id", "items"],
+				"properties": {
+					"id": {"type": "integer", "format": "int64"},
+					"items": {"type": "array", "minItems": 1, "items": {
+						"type": "object",
+						"required": ["sku", "quantity"],
+						"properties": {
+							"sku": {"type": "string", "minLength": 1},
+							"quantity": {"type": "integer", "exclusiveMinimum": 0},
+							"price": {"type": "number"}
+						}
+					}},
+					"coupon": {"anyOf": [{"$ref": "#/definitions/Coupon"}, {"type": "null"}]}
+				}
+			},
+			"Coupon": {"type": "string", "enum": ["FREE", "HALF"]}
+		}
+	}`), &schema))
+
+	source, err := jsonschema.JSONSchemaToGo(&schema, "partner", "OrderEvent")
+	require.NoError(t, err)
+	assert.Equal(t, `// Code generated by valise. DO NOT EDIT.
+
+package partner
+
+import (
+	"time"
+
+	"github.com/DimmyJing/valise/jsonschema"
+)
+
+// An order event sent by the partner.
+type OrderEvent struct {
+	EventID string `+"`"+`json:"event_id" validate:"format=uuid"`+"`"+`
+	// The kind of event.
+	Type      OrderEventType `+"`"+`json:"type"`+"`"+`
+	CreatedAt time.Time      `+"`"+`json:"created_at"`+"`"+`
+	Order     Order          `+"`"+`json:"order"`+"`"+`
+	Note      *string        `+"`"+`json:"note,omitempty" validate:"max=200"`+"`"+`
+	Retry     *int           `+"`"+`json:"retry,optional" validate:"min=0,max=5" schema:"default=0"`+"`"+`
+	// Deprecated: do not use.
+	LegacyCode *string           `+"`"+`json:"legacyCode,optional" validate:"pattern=^[A-Z]{3}$" schema:"deprecated"`+"`"+`
+	Metadata   map[string]string `+"`"+`json:"metadata,omitempty"`+"`"+`
+}
+
+// The kind of event.
+type OrderEventType string
+
+const (
+	OrderEventTypeOrderCreated   OrderEventType = "order.created"
+	OrderEventTypeOrderCancelled OrderEventType = "order.cancelled"
+)
+
+func (OrderEventType) Members() []string {
+	return jsonschema.EnumMembers(OrderEventTypeOrderCreated, OrderEventTypeOrderCancelled)
+}
+
+type Order struct {
+	ID     int64            `+"`"+`json:"id"`+"`"+`
+	Items  []OrderItemsItem `+"`"+`json:"items" validate:"min=1"`+"`"+`
+	Coupon *Coupon          `+"`"+`json:"coupon,omitempty"`+"`"+`
+}
+
+type OrderItemsItem struct {
+	Sku      string   `+"`"+`json:"sku" validate:"min=1"`+"`"+`
+	Quantity int      `+"`"+`json:"quantity" schema:"exclusiveMin=0"`+"`"+`
+	Price    *float64 `+"`"+`json:"price,optional"`+"`"+`
+}
+
+type Coupon string
+
+const (
+	CouponFREE Coupon = "FREE"
+	CouponHALF Coupon = "HALF"
+)
+
+func (Coupon) Members() []string {
+	return jsonschema.EnumMembers(CouponFREE, CouponHALF)
+}
+`, source)
+
+	//nolint:exhaustruct
+	_, err = jsonschema.JSONSchemaToGo(&jsonschema.JSONSchema{Ref: "#/$defs/Missing"}, "partner", "Event")
+	require.Error(t, err)
+}
@@ -2,19 +2,34 @@ package jsonschema
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"slices"
 	"strconv"
-	"strings"
 	"time"
 )
 
-func ValueToAny(value reflect.Value) (any, error) { //nolint:cyclop,funlen,gocognit,gocyclo
+var errCyclicValue = errors.New("cyclic value")
+
+func ValueToAny(value reflect.Value) (any, error) {
+	return encoder{visiting: map[uintptr]struct{}{}}.valueToAny(value)
+}
+
+// encoder tracks the pointers on the path to the current value, so cyclic values fail instead of recursing forever.
+type encoder struct {
+	visiting map[uintptr]struct{}
+}
+
+func (e encoder) valueToAny(value reflect.Value) (any, error) { //nolint:cyclop,funlen,gocognit,gocyclo
 	if value.Type().Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()) {
 		return value.Interface(), nil
 	}
 
+	if text, isText, err := marshalText(value); isText {
+		return text, err
+	}
+
 	//nolint:exhaustive
 	switch value.Type().Kind() {
 	case reflect.Bool:
@@ -29,7 +44,7 @@ func ValueToAny(value reflect.Value) (any, error) { //nolint:cyclop,funlen,gocog
 		result := make([]any, value.Len())
 
 		for idx := range value.Len() {
-			arrayVal, err := ValueToAny(value.Index(idx))
+			arrayVal, err := e.valueToAny(value.Index(idx))
 			if err != nil {
 				return nil, fmt.Errorf("failed to convert array value at idx %d: %w", idx, err)
 			}
@@ -39,15 +54,21 @@ func ValueToAny(value reflect.Value) (any, error) { //nolint:cyclop,funlen,gocog
 
 		return result, nil
 	case reflect.Interface:
-		if value.NumMethod() != 0 {
+		registered, isUnion := lookupUnion(value.Type())
+		if value.NumMethod() != 0 && !isUnion {
 			return nil, fmt.Errorf("invalid interface type: %w", errReflectType)
 		}
 
 		if value.IsNil() {
-			return value.Interface(), nil
+			//nolint:nilnil
+			return nil, nil
+		}
+
+		if isUnion {
+			return e.unionToAny(registered, value.Elem())
 		}
 
-		return ValueToAny(value.Elem())
+		return e.valueToAny(value.Elem())
 	case reflect.Map:
 		if value.Type().Key().Kind() != reflect.String {
 			return nil, fmt.Errorf("invalid map key type %s: %w", value.Type().Key().Kind().String(), errReflectType)
@@ -57,7 +78,7 @@ func ValueToAny(value reflect.Value) (any, error) { //nolint:cyclop,funlen,gocog
 
 		iter := value.MapRange()
 		for iter.Next() {
-			mapValue, err := ValueToAny(iter.Value())
+			mapValue, err := e.valueToAny(iter.Value())
 			if err != nil {
 				return nil, fmt.Errorf("failed to convert map value at key %s: %w", iter.Key().String(), err)
 			}
@@ -72,7 +93,14 @@ func ValueToAny(value reflect.Value) (any, error) { //nolint:cyclop,funlen,gocog
 			return nil, nil
 		}
 
-		ptrValue, err := ValueToAny(value.Elem())
+		if _, found := e.visiting[value.Pointer()]; found {
+			return nil, fmt.Errorf("pointer to %s: %w", value.Type().Elem(), errCyclicValue)
+		}
+
+		e.visiting[value.Pointer()] = struct{}{}
+		defer delete(e.visiting, value.Pointer())
+
+		ptrValue, err := e.valueToAny(value.Elem())
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert pointer value: %w", err)
 		}
@@ -86,7 +114,7 @@ func ValueToAny(value reflect.Value) (any, error) { //nolint:cyclop,funlen,gocog
 		result := make([]any, value.Len())
 
 		for idx := range value.Len() {
-			sliceVal, err := ValueToAny(value.Index(idx))
+			sliceVal, err := e.valueToAny(value.Index(idx))
 			if err != nil {
 				return nil, fmt.Errorf("failed to convert slice value at idx %d: %w", idx, err)
 			}
@@ -102,39 +130,40 @@ func ValueToAny(value reflect.Value) (any, error) { //nolint:cyclop,funlen,gocog
 			return timeValue, nil
 		}
 
-		result := make(map[string]any)
-
-		for idx := range value.NumField() {
-			field := value.Type().Field(idx)
-			if !field.IsExported() {
-				continue
+		if isOptional(value.Type()) {
+			if !value.FieldByName("Set").Bool() || value.FieldByName("Null").Bool() {
+				//nolint:nilnil
+				return nil, nil
 			}
 
-			fieldName := strings.ToLower(string(field.Name[0])) + field.Name[1:]
-			optional := false
-
-			//nolint:nestif
-			if jsonTag, found := field.Tag.Lookup("json"); found {
-				splitTags := strings.Split(jsonTag, ",")
-				if len(splitTags) > 0 {
-					if splitTags[0] == "-" && len(splitTags) == 1 {
-						continue
-					} else if splitTags[0] != "" {
-						fieldName = splitTags[0]
-					}
-				}
+			return e.valueToAny(value.FieldByName("Value"))
+		}
 
-				if slices.Contains(splitTags[1:], "omitempty") {
-					optional = true
-				}
+		result := make(map[string]any)
+
+		for _, field := range StructFields(value.Type()) {
+			fieldName := field.JSONName
+
+			structField, err := value.FieldByIndexErr(field.Index)
+			if err != nil {
+				// promoted through a nil embedded pointer
+				continue
 			}
 
-			fieldValue, err := ValueToAny(value.Field(idx))
+			fieldValue, err := e.valueToAny(structField)
 			if err != nil {
 				return nil, fmt.Errorf("failed to convert struct field %s: %w", fieldName, err)
 			}
 
-			if optional && (fieldValue == nil || reflect.ValueOf(fieldValue).IsZero()) {
+			if field.OmitEmpty && (fieldValue == nil || reflect.ValueOf(fieldValue).IsZero()) {
+				continue
+			}
+
+			if isOptional(field.Type) {
+				if !structField.FieldByName("Set").Bool() {
+					continue
+				}
+			} else if field.NotNull && fieldValue == nil {
 				continue
 			}
 
@@ -147,10 +176,99 @@ func ValueToAny(value reflect.Value) (any, error) { //nolint:cyclop,funlen,gocog
 	}
 }
 
-func AnyToValue(anyVal any, value reflect.Value) error { //nolint:funlen,gocognit,gocyclo,cyclop,maintidx
+type DecodeError struct {
+	Path string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+type DecodeOption interface {
+	decodeOption()
+}
+
+type withAllowUnknownFields struct{}
+
+func (withAllowUnknownFields) decodeOption() {}
+
+// WithAllowUnknownFields makes AnyToValue ignore object keys that do not match a struct field.
+func WithAllowUnknownFields() withAllowUnknownFields {
+	return withAllowUnknownFields{}
+}
+
+type withSkippedFields []string
+
+func (withSkippedFields) decodeOption() {}
+
+// WithSkippedFields makes AnyToValue leave the top level fields of the given json names as they are, even if they are
+// required, e.g. out fields that are set from the response headers rather than the json.
+func WithSkippedFields(names ...string) withSkippedFields {
+	return withSkippedFields(names)
+}
+
+type decoder struct {
+	allowUnknownFields bool
+	skippedFields      []string
+}
+
+// AnyToValue converts anyVal into value. Errors carry the JSON pointer of the offending value as a *DecodeError.
+func AnyToValue(anyVal any, value reflect.Value, options ...DecodeOption) error {
+	var dec decoder
+
+	for _, option := range options {
+		switch option := option.(type) {
+		case withAllowUnknownFields:
+			dec.allowUnknownFields = true
+		case withSkippedFields:
+			dec.skippedFields = append(dec.skippedFields, option...)
+		}
+	}
+
+	return dec.decode(anyVal, value, "")
+}
+
+func (d decoder) decode( //nolint:funlen,gocognit,gocyclo,cyclop,maintidx
+	anyVal any,
+	value reflect.Value,
+	path string,
+) (err error) {
+	defer func() {
+		var decodeErr *DecodeError
+		if err != nil && !errors.As(err, &decodeErr) {
+			err = &DecodeError{Path: path, Err: err}
+		}
+	}()
+
 	if !value.CanSet() {
 		return fmt.Errorf("value is not settable: %w", errReflectType)
 	}
+
+	if isOptional(value.Type()) {
+		value.SetZero()
+		value.FieldByName("Set").SetBool(true)
+
+		if anyVal == nil {
+			value.FieldByName("Null").SetBool(true)
+
+			return nil
+		}
+
+		return d.decode(anyVal, value.FieldByName("Value"), path)
+	}
+
+	if handled, err := unmarshalCustom(anyVal, value); handled {
+		return err
+	}
 	//nolint:exhaustive
 	switch value.Type().Kind() {
 	case reflect.Bool:
@@ -182,7 +300,7 @@ func AnyToValue(anyVal any, value reflect.Value) error { //nolint:funlen,gocogni
 		if anyElem.CanConvert(value.Type()) {
 			value.Set(anyElem.Convert(value.Type()))
 		} else if stringVal, isString := anyVal.(string); isString {
-			intVal, err := strconv.ParseInt(stringVal, 10, 64)
+			intVal, err := parseIntString(value.Type(), stringVal)
 			if err != nil {
 				return fmt.Errorf("invalid int string value %s: %w", stringVal, errReflectType)
 			}
@@ -191,7 +309,7 @@ func AnyToValue(anyVal any, value reflect.Value) error { //nolint:funlen,gocogni
 		} else if stringSliceVal, isStringSlice := anyVal.([]string); isStringSlice {
 			if len(stringSliceVal) != 1 {
 				return fmt.Errorf("invalid string slice length %d: %w", len(stringSliceVal), errReflectType)
-			} else if res, err := strconv.ParseInt(stringSliceVal[0], 10, 64); err != nil {
+			} else if res, err := parseIntString(value.Type(), stringSliceVal[0]); err != nil {
 				return fmt.Errorf("invalid int string value %s: %w", stringSliceVal[0], errReflectType)
 			} else {
 				value.SetInt(res)
@@ -255,7 +373,7 @@ func AnyToValue(anyVal any, value reflect.Value) error { //nolint:funlen,gocogni
 			}
 
 			for idx, arrayElem := range arrayVal {
-				if err := AnyToValue(arrayElem, value.Index(idx)); err != nil {
+				if err := d.decode(arrayElem, value.Index(idx), path+"/"+strconv.Itoa(idx)); err != nil {
 					return fmt.Errorf("failed to set array value at idx %d: %w", idx, err)
 				}
 			}
@@ -265,7 +383,7 @@ func AnyToValue(anyVal any, value reflect.Value) error { //nolint:funlen,gocogni
 			}
 
 			for idx, arrayElem := range strArrayVal {
-				if err := AnyToValue(arrayElem, value.Index(idx)); err != nil {
+				if err := d.decode(arrayElem, value.Index(idx), path+"/"+strconv.Itoa(idx)); err != nil {
 					return fmt.Errorf("failed to set str array value at idx %d: %w", idx, err)
 				}
 			}
@@ -273,6 +391,10 @@ func AnyToValue(anyVal any, value reflect.Value) error { //nolint:funlen,gocogni
 			return fmt.Errorf("cannot set array value from %v: %w", anyVal, errReflectType)
 		}
 	case reflect.Interface:
+		if registered, isUnion := lookupUnion(value.Type()); isUnion {
+			return d.decodeUnion(registered, anyVal, value, path)
+		}
+
 		if value.NumMethod() != 0 {
 			return fmt.Errorf("invalid interface type: %w", errReflectType)
 		}
@@ -294,7 +416,7 @@ func AnyToValue(anyVal any, value reflect.Value) error { //nolint:funlen,gocogni
 
 			for key, mapElem := range mapVal {
 				mapValue := reflect.New(value.Type().Elem()).Elem()
-				if err := AnyToValue(mapElem, mapValue); err != nil {
+				if err := d.decode(mapElem, mapValue, path+"/"+escapePointer(key)); err != nil {
 					return fmt.Errorf("failed to set map value at key %s: %w", key, err)
 				}
 
@@ -311,7 +433,7 @@ func AnyToValue(anyVal any, value reflect.Value) error { //nolint:funlen,gocogni
 				value.Set(reflect.New(value.Type().Elem()))
 			}
 
-			err := AnyToValue(anyVal, value.Elem())
+			err := d.decode(anyVal, value.Elem(), path)
 			if err != nil {
 				return fmt.Errorf("failed to convert pointer value %v: %w", anyVal, err)
 			}
@@ -331,7 +453,7 @@ func AnyToValue(anyVal any, value reflect.Value) error { //nolint:funlen,gocogni
 				value.Set(reflect.MakeSlice(value.Type(), len(sliceVal), len(sliceVal)))
 
 				for idx, sliceElem := range sliceVal {
-					if err := AnyToValue(sliceElem, value.Index(idx)); err != nil {
+					if err := d.decode(sliceElem, value.Index(idx), path+"/"+strconv.Itoa(idx)); err != nil {
 						return fmt.Errorf("failed to set slice value at idx %d: %w", idx, err)
 					}
 				}
@@ -339,7 +461,7 @@ func AnyToValue(anyVal any, value reflect.Value) error { //nolint:funlen,gocogni
 				value.Set(reflect.MakeSlice(value.Type(), len(sliceStrVal), len(sliceStrVal)))
 
 				for idx, sliceElem := range sliceStrVal {
-					if err := AnyToValue(sliceElem, value.Index(idx)); err != nil {
+					if err := d.decode(sliceElem, value.Index(idx), path+"/"+strconv.Itoa(idx)); err != nil {
 						return fmt.Errorf("failed to set str slice value at idx %d: %w", idx, err)
 					}
 				}
@@ -385,49 +507,60 @@ func AnyToValue(anyVal any, value reflect.Value) error { //nolint:funlen,gocogni
 		} else if mapVal, isMapVal := anyVal.(map[string]any); isMapVal {
 			processedKeys := make(map[string]struct{})
 
-			for idx := range value.NumField() {
-				field := value.Type().Field(idx)
-				if !field.IsExported() {
+			for _, field := range StructFields(value.Type()) {
+				fieldName := field.JSONName
+
+				if path == "" && slices.Contains(d.skippedFields, fieldName) {
+					if _, found := mapVal[fieldName]; found {
+						processedKeys[fieldName] = struct{}{}
+					}
+
 					continue
 				}
 
-				fieldName := strings.ToLower(string(field.Name[0])) + field.Name[1:]
-				optional := false
-
-				if jsonTag, found := field.Tag.Lookup("json"); found {
-					splitTags := strings.Split(jsonTag, ",")
-					if len(splitTags) > 0 {
-						if splitTags[0] == "-" && len(splitTags) == 1 {
-							continue
-						} else if splitTags[0] != "" {
-							fieldName = splitTags[0]
+				if fieldVal, found := mapVal[fieldName]; found {
+					if fieldVal == nil && field.NotNull {
+						return &DecodeError{
+							Path: path + "/" + escapePointer(fieldName),
+							Err:  fmt.Errorf("optional field %s must not be null: %w", fieldName, errReflectType),
 						}
 					}
 
-					if slices.Contains(splitTags[1:], "omitempty") {
-						optional = true
+					structField, err := FieldByIndex(value, field.Index)
+					if err != nil {
+						return fmt.Errorf("failed to set struct field %s: %w", fieldName, err)
 					}
-				}
 
-				if fieldVal, found := mapVal[fieldName]; found {
-					if err := AnyToValue(fieldVal, value.Field(idx)); err != nil {
+					if err := d.decode(fieldVal, structField, path+"/"+escapePointer(fieldName)); err != nil {
 						return fmt.Errorf("failed to set struct field %s: %w", fieldName, err)
 					}
 
 					processedKeys[fieldName] = struct{}{}
-				} else if !optional {
-					return fmt.Errorf("missing required field %s: %w", fieldName, errReflectType)
+				} else if !field.Optional {
+					return &DecodeError{
+						Path: path + "/" + escapePointer(fieldName),
+						Err:  fmt.Errorf("missing required field %s: %w", fieldName, errReflectType),
+					}
 				} else {
 					continue
 				}
 			}
 
-			if len(processedKeys) < len(mapVal) {
+			if !d.allowUnknownFields && len(processedKeys) < len(mapVal) {
+				extraKeys := []string{}
+
 				for key := range mapVal {
 					if _, found := processedKeys[key]; !found {
-						return fmt.Errorf("extra field in struct conversion %s: %w", key, errReflectType)
+						extraKeys = append(extraKeys, key)
 					}
 				}
+
+				slices.Sort(extraKeys)
+
+				return &DecodeError{
+					Path: path + "/" + escapePointer(extraKeys[0]),
+					Err:  fmt.Errorf("extra field in struct conversion %s: %w", extraKeys[0], errReflectType),
+				}
 			}
 		} else {
 			return fmt.Errorf("invalid struct value %v: %w", anyVal, errReflectType)
@@ -438,3 +571,20 @@ func AnyToValue(anyVal any, value reflect.Value) error { //nolint:funlen,gocogni
 
 	return nil
 }
+
+// parseIntString parses str, a path, query, header or cookie value, as an int, or as a duration such as 1m30s for
+// time.Duration. Durations are integers of nanoseconds in json, like in encoding/json.
+func parseIntString(typ reflect.Type, str string) (int64, error) {
+	if typ == reflect.TypeOf(time.Duration(0)) {
+		if duration, err := time.ParseDuration(str); err == nil {
+			return int64(duration), nil
+		}
+	}
+
+	intVal, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse int %s: %w", str, err)
+	}
+
+	return intVal, nil
+}
@@ -407,3 +407,58 @@ func TestAnyToValueError(t *testing.T) { //nolint:funlen
 	err = jsonschema.AnyToValue(true, reflect.ValueOf(&valStruct4).Elem())
 	require.Error(t, err)
 }
+
+func TestAnyToValueDecodeError(t *testing.T) {
+	t.Parallel()
+
+	type testItem struct {
+		Price float64 `json:"price"`
+	}
+
+	type testVal struct {
+		Items []testItem `json:"items"`
+	}
+
+	input := map[string]any{
+		"items": []any{
+			map[string]any{"price": 1.0},
+			map[string]any{"price": "abc"},
+		},
+	}
+
+	var val testVal
+
+	err := jsonschema.AnyToValue(input, reflect.ValueOf(&val).Elem())
+
+	var decodeErr *jsonschema.DecodeError
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, "/items/1/price", decodeErr.Path)
+
+	err = jsonschema.AnyToValue(map[string]any{"items": []any{map[string]any{}}}, reflect.ValueOf(&val).Elem())
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, "/items/0/price", decodeErr.Path)
+
+	input = map[string]any{"items": []any{}, "b/c": 1, "a": 1}
+	err = jsonschema.AnyToValue(input, reflect.ValueOf(&val).Elem())
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, "/a", decodeErr.Path)
+
+	delete(input, "a")
+	err = jsonschema.AnyToValue(input, reflect.ValueOf(&val).Elem())
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, "/b~1c", decodeErr.Path)
+
+	err = jsonschema.AnyToValue(input, reflect.ValueOf(&val).Elem(), jsonschema.WithAllowUnknownFields())
+	require.NoError(t, err)
+
+	// skipped fields are left as they are, even if they are required or given.
+	val.Items = []testItem{{Price: 1}}
+
+	err = jsonschema.AnyToValue(map[string]any{}, reflect.ValueOf(&val).Elem(), jsonschema.WithSkippedFields("items"))
+	require.NoError(t, err)
+
+	err = jsonschema.AnyToValue(map[string]any{"items": []any{}}, reflect.ValueOf(&val).Elem(),
+		jsonschema.WithSkippedFields("items"))
+	require.NoError(t, err)
+	assert.Equal(t, []testItem{{Price: 1}}, val.Items)
+}
@@ -0,0 +1,43 @@
+package jsonschema
+
+import "reflect"
+
+// Optional is a field that tells apart being absent, being explicitly null and being set, as needed by PATCH style
+// inputs. Its schema is an optional nullable T, and AnyToValue sets Set whenever the key is present.
+type Optional[T any] struct {
+	Value T
+	Set   bool
+	Null  bool
+}
+
+// Some returns an Optional set to value.
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{Value: value, Set: true, Null: false}
+}
+
+// Null returns an Optional explicitly set to null.
+func Null[T any]() Optional[T] {
+	var zero T
+
+	return Optional[T]{Value: zero, Set: true, Null: true}
+}
+
+// Get returns the value and whether it was set to something other than null.
+func (o Optional[T]) Get() (T, bool) {
+	return o.Value, o.Set && !o.Null
+}
+
+func (Optional[T]) optionalElem() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+type optionalField interface {
+	optionalElem() reflect.Type
+}
+
+//nolint:gochecknoglobals
+var optionalInterface = reflect.TypeOf((*optionalField)(nil)).Elem()
+
+func isOptional(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ.Implements(optionalInterface)
+}
@@ -0,0 +1,64 @@
+package jsonschema_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DimmyJing/valise/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TestPatch struct {
+	Name  jsonschema.Optional[string]       `json:"name"  validate:"min=2"`
+	Note  jsonschema.Optional[string]       `json:"note"`
+	Count jsonschema.Optional[int]          `json:"count"`
+	Tree  jsonschema.Optional[TestTreeNode] `json:"tree"`
+}
+
+func TestOptional(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	schema, err := jsonschema.AnyToSchema(reflect.TypeOf(TestPatch{})) //nolint:exhaustruct
+	require.NoError(t, err)
+	assert.Empty(t, schema.Required)
+
+	name, _ := schema.Properties.Get("name")
+	assert.Equal(t, "string", name.Type)
+	assert.True(t, name.Nullable)
+	assert.Equal(t, 2, name.MinLength)
+
+	ts, err := jsonschema.JSONSchemaToTS(schema, "export type TestPatch = ")
+	require.NoError(t, err)
+	assert.Contains(t, ts, "  name?: string | null;\n")
+	assert.Contains(t, ts, "  count?: number | null;\n")
+
+	var patch TestPatch
+
+	err = jsonschema.AnyToValue(map[string]any{"name": "ab", "note": nil}, reflect.ValueOf(&patch).Elem())
+	require.NoError(t, err)
+	assert.Equal(t, jsonschema.Some("ab"), patch.Name)
+	assert.Equal(t, jsonschema.Null[string](), patch.Note)
+	assert.False(t, patch.Count.Set)
+
+	value, ok := patch.Name.Get()
+	assert.True(t, ok)
+	assert.Equal(t, "ab", value)
+
+	_, ok = patch.Note.Get()
+	assert.False(t, ok)
+
+	anyVal, err := jsonschema.ValueToAny(reflect.ValueOf(patch))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "ab", "note": nil}, anyVal)
+
+	assert.Empty(t, jsonschema.ValidateValue(reflect.ValueOf(patch)))
+
+	patch.Name = jsonschema.Some("a")
+	errs := jsonschema.ValidateValue(reflect.ValueOf(patch))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "/name", errs[0].Path)
+
+	patch.Name = jsonschema.Null[string]()
+	assert.Empty(t, jsonschema.ValidateValue(reflect.ValueOf(patch)))
+}
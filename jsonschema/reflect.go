@@ -4,8 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"slices"
-	"strings"
 	"time"
 
 	orderedmap "github.com/wk8/go-ordered-map/v2"
@@ -29,7 +27,7 @@ var enumInterface = reflect.TypeOf((*EnumMember)(nil)).Elem()
 
 var errReflectType = errors.New("invalid reflect.Type")
 
-func convertType(value reflect.Type) (*JSONSchema, error) { //nolint:funlen,gocognit,gocyclo,cyclop
+func (r *Reflector) convertInline(value reflect.Type) (*JSONSchema, error) { //nolint:funlen,gocognit,gocyclo,cyclop
 	var schema JSONSchema
 
 	if desc, found := getDescription(value.PkgPath(), value.Name(), ""); found {
@@ -59,16 +57,23 @@ func convertType(value reflect.Type) (*JSONSchema, error) { //nolint:funlen,goco
 		//nolint:goconst
 		schema.Type = "array"
 
-		val, err := convertType(value.Elem())
+		val, err := r.convertType(value.Elem())
 		if err != nil {
 			return nil, fmt.Errorf("error converting array element type: %w", err)
 		}
 
 		schema.Items = val
-		schema.MaxItems = value.Len()
+		schema.SetMaxItems(value.Len())
 		schema.MinItems = value.Len()
 	case reflect.Interface:
-		if value.NumMethod() == 0 {
+		if registered, isUnion := lookupUnion(value); isUnion {
+			unionSchema, err := r.unionSchema(registered)
+			if err != nil {
+				return nil, err
+			}
+
+			schema = *unionSchema
+		} else if value.NumMethod() == 0 {
 			schema.boolean = new(bool)
 			*schema.boolean = true
 		} else {
@@ -78,18 +83,20 @@ func convertType(value reflect.Type) (*JSONSchema, error) { //nolint:funlen,goco
 		//nolint:goconst
 		schema.Type = "object"
 
-		val, err := convertType(value.Elem())
+		val, err := r.convertType(value.Elem())
 		if err != nil {
 			return nil, fmt.Errorf("error converting map element type: %w", err)
 		}
 
 		schema.AdditionalProperties = val
 	case reflect.Ptr:
-		val, err := convertType(value.Elem())
+		val, err := r.convertType(value.Elem())
 		if err != nil {
 			return nil, fmt.Errorf("error converting pointer type: %w", err)
 		}
 
+		val.Nullable = true
+
 		return val, nil
 	case reflect.Slice:
 		if value.Elem().Kind() == reflect.Uint8 {
@@ -99,7 +106,7 @@ func convertType(value reflect.Type) (*JSONSchema, error) { //nolint:funlen,goco
 		} else {
 			schema.Type = "array"
 
-			val, err := convertType(value.Elem())
+			val, err := r.convertType(value.Elem())
 			if err != nil {
 				return nil, fmt.Errorf("error converting slice element type: %w", err)
 			}
@@ -123,48 +130,32 @@ func convertType(value reflect.Type) (*JSONSchema, error) { //nolint:funlen,goco
 		} else {
 			schema.Type = "object"
 
-			for i := range value.NumField() {
-				field := value.Field(i)
-				if !field.IsExported() {
-					continue
-				}
-
-				fieldName := strings.ToLower(string(field.Name[0])) + field.Name[1:]
-				optional := false
-
-				if jsonTag, found := field.Tag.Lookup("json"); found {
-					splitTags := strings.Split(jsonTag, ",")
-					if len(splitTags) > 0 {
-						if splitTags[0] == "-" && len(splitTags) == 1 {
-							continue
-						} else if splitTags[0] != "" {
-							fieldName = splitTags[0]
-						}
-					}
-
-					if slices.Contains(splitTags[1:], "omitempty") {
-						optional = true
-					}
-				}
+			for _, field := range StructFields(value) {
+				fieldName := field.JSONName
 
 				if schema.Properties == nil {
 					schema.Properties = orderedmap.New[string, *JSONSchema]()
 				}
 
-				property, err := convertType(field.Type)
+				property, err := r.convertType(field.Type)
 				if err != nil {
 					return nil, fmt.Errorf("error converting struct field %s: %w", fieldName, err)
 				}
 
-				if desc, found := getDescription(value.PkgPath(), value.Name(), field.Name); found {
+				if desc, found := getDescription(field.Owner.PkgPath(), field.Owner.Name(), field.Name); found {
 					property.Description = desc
 				}
 
+				if err := applyValidateTag(property, field.StructField); err != nil {
+					return nil, fmt.Errorf("error applying validate tag of struct field %s: %w", fieldName, err)
+				}
+
 				property.Title = fieldName
+				property.Nullable = property.Nullable && !field.NotNull
 
 				schema.Properties.Set(fieldName, property)
 
-				if !optional {
+				if !field.Optional {
 					schema.Required = append(schema.Required, fieldName)
 				}
 			}
@@ -184,12 +175,61 @@ func convertType(value reflect.Type) (*JSONSchema, error) { //nolint:funlen,goco
 }
 
 func AnyToSchema(value reflect.Type) (*JSONSchema, error) {
-	return convertType(value)
+	reflector := newInlineReflector()
+
+	schema, err := reflector.AnyToSchema(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return reflector.withDefinitions(schema), nil
+}
+
+// AnyToSchema converts value to a schema, referencing named struct types.
+func (r *Reflector) AnyToSchema(value reflect.Type) (*JSONSchema, error) {
+	return r.convertType(value)
 }
 
 var errInvalidTag = errors.New("invalid tag")
 
-func RequestBodyToSchema(value reflect.Type) (*JSONSchema, error) { //nolint:funlen,cyclop
+func RequestBodyToSchema(value reflect.Type) (*JSONSchema, error) {
+	reflector := newInlineReflector()
+
+	schema, err := reflector.RequestBodyToSchema(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return reflector.withDefinitions(schema), nil
+}
+
+// RequestBodyToSchema converts an rpc input type to an inline schema, referencing the named struct types it uses.
+func (r *Reflector) RequestBodyToSchema(value reflect.Type) (*JSONSchema, error) {
+	return r.bodyToSchema(value, "in")
+}
+
+// ResponseBodyToSchema converts an rpc output type to a schema, leaving out fields tagged with out.
+func ResponseBodyToSchema(value reflect.Type) (*JSONSchema, error) {
+	reflector := newInlineReflector()
+
+	schema, err := reflector.ResponseBodyToSchema(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return reflector.withDefinitions(schema), nil
+}
+
+// ResponseBodyToSchema converts an rpc output type to an inline schema, referencing the named struct types it uses.
+func (r *Reflector) ResponseBodyToSchema(value reflect.Type) (*JSONSchema, error) {
+	if value.Kind() != reflect.Struct {
+		return r.convertType(value)
+	}
+
+	return r.bodyToSchema(value, "out")
+}
+
+func (r *Reflector) bodyToSchema(value reflect.Type, excludeTag string) (*JSONSchema, error) { //nolint:funlen,cyclop
 	if value.Kind() != reflect.Struct {
 		return nil, fmt.Errorf("invalid reflect type %s, expected struct: %w", value.Kind().String(), errReflectType)
 	}
@@ -203,32 +243,10 @@ func RequestBodyToSchema(value reflect.Type) (*JSONSchema, error) { //nolint:fun
 	schema.Title = value.Name()
 	schema.Type = "object"
 
-	for i := range value.NumField() {
-		field := value.Field(i)
-		if !field.IsExported() {
-			continue
-		}
+	for _, field := range StructFields(value) {
+		fieldName := field.JSONName
 
-		fieldName := strings.ToLower(string(field.Name[0])) + field.Name[1:]
-		optional := false
-
-		//nolint:nestif
-		if jsonTag, found := field.Tag.Lookup("json"); found {
-			splitTags := strings.Split(jsonTag, ",")
-			if len(splitTags) > 0 {
-				if splitTags[0] == "-" && len(splitTags) == 1 {
-					continue
-				} else if splitTags[0] != "" {
-					fieldName = splitTags[0]
-				}
-			}
-
-			if slices.Contains(splitTags[1:], "omitempty") {
-				optional = true
-			}
-		}
-
-		if _, found := field.Tag.Lookup("in"); found {
+		if _, found := field.Tag.Lookup(excludeTag); found {
 			continue
 		}
 
@@ -236,20 +254,25 @@ func RequestBodyToSchema(value reflect.Type) (*JSONSchema, error) { //nolint:fun
 			schema.Properties = orderedmap.New[string, *JSONSchema]()
 		}
 
-		property, err := AnyToSchema(field.Type)
+		property, err := r.convertType(field.Type)
 		if err != nil {
 			return nil, fmt.Errorf("error converting struct field %s: %w", fieldName, err)
 		}
 
-		if desc, found := getDescription(value.PkgPath(), value.Name(), field.Name); found {
+		if desc, found := getDescription(field.Owner.PkgPath(), field.Owner.Name(), field.Name); found {
 			property.Description = desc
 		}
 
+		if err := applyValidateTag(property, field.StructField); err != nil {
+			return nil, fmt.Errorf("error applying validate tag of struct field %s: %w", fieldName, err)
+		}
+
 		property.Title = fieldName
+		property.Nullable = property.Nullable && !field.NotNull
 
 		schema.Properties.Set(fieldName, property)
 
-		if !optional {
+		if !field.Optional {
 			schema.Required = append(schema.Required, fieldName)
 		}
 	}
@@ -259,37 +282,31 @@ func RequestBodyToSchema(value reflect.Type) (*JSONSchema, error) { //nolint:fun
 	return &schema, nil
 }
 
-func ParametersToSchema(value reflect.Type, defaultToQuery bool) ([]OpenAPIParameter, error) { //nolint:cyclop
-	params := []OpenAPIParameter{}
+func ParametersToSchema(value reflect.Type, defaultToQuery bool) ([]OpenAPIParameter, error) {
+	reflector := newInlineReflector()
 
-	for i := range value.NumField() {
-		field := value.Field(i)
-		if !field.IsExported() {
-			continue
-		}
+	params, err := reflector.ParametersToSchema(value, defaultToQuery)
+	if err != nil {
+		return nil, err
+	}
 
-		fieldName := strings.ToLower(string(field.Name[0])) + field.Name[1:]
-		optional := false
-		paramIn := "query"
+	for _, param := range params {
+		reflector.withDefinitions(param.Schema)
+	}
 
-		//nolint:nestif
-		if jsonTag, found := field.Tag.Lookup("json"); found {
-			splitTags := strings.Split(jsonTag, ",")
-			if len(splitTags) > 0 {
-				if splitTags[0] == "-" && len(splitTags) == 1 {
-					continue
-				} else if splitTags[0] != "" {
-					fieldName = splitTags[0]
-				}
-			}
+	return params, nil
+}
 
-			if slices.Contains(splitTags[1:], "omitempty") {
-				optional = true
-			}
-		}
+//nolint:cyclop
+func (r *Reflector) ParametersToSchema(value reflect.Type, defaultToQuery bool) ([]OpenAPIParameter, error) {
+	params := []OpenAPIParameter{}
+
+	for _, field := range StructFields(value) {
+		fieldName := field.JSONName
+		paramIn := "query"
 
 		if inTag, found := field.Tag.Lookup("in"); found {
-			if inTag != "path" && inTag != "query" {
+			if inTag != "path" && inTag != "query" && inTag != "header" && inTag != "cookie" {
 				return nil, fmt.Errorf("invalid value for in tag %s: %w", inTag, errInvalidTag)
 			}
 
@@ -298,24 +315,76 @@ func ParametersToSchema(value reflect.Type, defaultToQuery bool) ([]OpenAPIParam
 			continue
 		}
 
-		property, err := convertType(field.Type)
+		property, err := r.convertType(field.Type)
 		if err != nil {
 			return nil, fmt.Errorf("error converting struct field %s: %w", fieldName, err)
 		}
 
-		desc, _ := getDescription(value.PkgPath(), value.Name(), field.Name)
+		desc, _ := getDescription(field.Owner.PkgPath(), field.Owner.Name(), field.Name)
 		property.Description = desc
 
+		if err := applyValidateTag(property, field.StructField); err != nil {
+			return nil, fmt.Errorf("error applying validate tag of struct field %s: %w", fieldName, err)
+		}
+
 		property.Title = fieldName
+		// parameters are absent rather than null.
+		property.Nullable = false
 
 		params = append(params, OpenAPIParameter{
 			Schema:      property,
 			Name:        fieldName,
 			In:          paramIn,
 			Description: property.Description,
-			Required:    !optional,
+			Required:    !field.Optional,
 		})
 	}
 
 	return params, nil
 }
+
+func ResponseHeadersToSchema(value reflect.Type) (map[string]OpenAPIHeader, error) {
+	reflector := newInlineReflector()
+
+	headers, err := reflector.ResponseHeadersToSchema(value)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, header := range headers {
+		reflector.withDefinitions(header.Schema)
+	}
+
+	return headers, nil
+}
+
+func (r *Reflector) ResponseHeadersToSchema(value reflect.Type) (map[string]OpenAPIHeader, error) {
+	headers := map[string]OpenAPIHeader{}
+
+	if value.Kind() != reflect.Struct {
+		return headers, nil
+	}
+
+	for _, field := range OutFields(value) {
+		if field.Tag.Get("out") != "header" {
+			continue
+		}
+
+		property, err := r.convertType(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("error converting struct field %s: %w", field.JSONName, err)
+		}
+
+		desc, _ := getDescription(field.Owner.PkgPath(), field.Owner.Name(), field.Name)
+		property.Description = desc
+		property.Nullable = false
+
+		headers[field.JSONName] = OpenAPIHeader{
+			Schema:      property,
+			Description: desc,
+			Required:    !field.Optional,
+		}
+	}
+
+	return headers, nil
+}
@@ -122,6 +122,10 @@ type TestSchema2 struct {
 	Hello2 string `json:"hello5" in:"path"`
 	Hello3 string `json:"-"`
 	Hello4 string `json:"hello4,omitempty"`
+	//nolint:tagalign
+	Hello6 string `json:"X-Hello6" in:"header"`
+	//nolint:tagalign
+	Hello7 string `json:"hello7,omitempty" in:"cookie"`
 }
 
 type TestSchema3 struct {
@@ -153,6 +157,14 @@ func TestParamReflect(t *testing.T) {
 	assert.False(t, schema[2].Required)
 	assert.Equal(t, "string", schema[2].Schema.Type)
 
+	assert.Equal(t, "X-Hello6", schema[3].Name)
+	assert.Equal(t, "header", schema[3].In)
+	assert.True(t, schema[3].Required)
+
+	assert.Equal(t, "hello7", schema[4].Name)
+	assert.Equal(t, "cookie", schema[4].In)
+	assert.False(t, schema[4].Required)
+
 	//nolint:exhaustruct
 	_, err = jsonschema.ParametersToSchema(reflect.TypeOf(TestSchema3{}), true)
 	assert.Error(t, err)
@@ -161,3 +173,95 @@ func TestParamReflect(t *testing.T) {
 	_, err = jsonschema.ParametersToSchema(reflect.TypeOf(TestSchema4{}), true)
 	assert.Error(t, err)
 }
+
+type TestNullable struct {
+	Nickname *string      `json:"nickname"`
+	Bio      *string      `json:"bio,optional"`
+	Avatar   *string      `json:"avatar,omitempty"`
+	Tags     []*string    `json:"tags,optional"`
+	Parent   *TestSchema3 `json:"parent"`
+}
+
+func TestNullableSchema(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	reflector := jsonschema.NewReflector("#/components/schemas/")
+
+	schema, err := reflector.AnyToSchema(reflect.TypeOf(TestNullable{})) //nolint:exhaustruct
+	require.NoError(t, err)
+
+	definition, _ := reflector.Definitions().Get("TestNullable")
+	assert.Equal(t, []string{"nickname", "parent"}, definition.Required)
+
+	schemaJSON, err := json.Marshal(definition.Properties)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"nickname": {"title": "nickname", "type": ["string", "null"]},
+		"bio": {"title": "bio", "type": "string"},
+		"avatar": {"title": "avatar", "type": ["string", "null"]},
+		"tags": {"title": "tags", "type": "array", "items": {"title": "string", "type": ["string", "null"]}},
+		"parent": {"title": "parent", "anyOf": [{"$ref": "#/components/schemas/TestSchema3"}, {"type": "null"}]}
+	}`, string(schemaJSON))
+
+	var decoded jsonschema.JSONSchema
+
+	nickname, _ := definition.Properties.Get("nickname")
+	nicknameJSON, err := json.Marshal(nickname)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(nicknameJSON, &decoded))
+	assert.Equal(t, "string", decoded.Type)
+	assert.True(t, decoded.Nullable)
+
+	ts, err := jsonschema.JSONSchemaToTS(definition, "export type TestNullable = ")
+	require.NoError(t, err)
+	assert.Equal(t, `export type TestNullable = {
+  nickname: string | null;
+  bio?: string;
+  avatar?: string | null;
+  tags?: (string | null)[];
+  parent: TestSchema3 | null;
+}`, ts)
+
+	assert.Equal(t, "#/components/schemas/TestNullable", schema.Ref)
+
+	anyVal, err := jsonschema.ValueToAny(reflect.ValueOf(TestNullable{})) //nolint:exhaustruct
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"nickname": nil, "tags": []any{}, "parent": nil}, anyVal)
+
+	var value TestNullable
+
+	err = jsonschema.AnyToValue(map[string]any{"nickname": nil, "parent": nil, "avatar": nil},
+		reflect.ValueOf(&value).Elem())
+	require.NoError(t, err)
+
+	err = jsonschema.AnyToValue(map[string]any{"nickname": nil, "parent": nil, "bio": nil},
+		reflect.ValueOf(&value).Elem())
+
+	var decodeErr *jsonschema.DecodeError
+
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, "/bio", decodeErr.Path)
+
+	err = jsonschema.AnyToValue(map[string]any{"parent": nil}, reflect.ValueOf(&value).Elem())
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, "/nickname", decodeErr.Path)
+}
+
+func TestNullableUntyped(t *testing.T) {
+	t.Parallel()
+
+	// schemas without a type, such as unions, cannot take "null" in a type array.
+	//nolint:exhaustruct
+	schema := &jsonschema.JSONSchema{
+		Title:    "shape",
+		AnyOf:    []*jsonschema.JSONSchema{{Type: "string"}, {Type: "integer"}},
+		Nullable: true,
+	}
+
+	schemaJSON, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"title": "shape",
+		"anyOf": [{"anyOf": [{"type": "string"}, {"type": "integer"}]}, {"type": "null"}]
+	}`, string(schemaJSON))
+}
@@ -0,0 +1,192 @@
+package jsonschema
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// Reflector converts types to schemas like the package level functions, except that named struct types and
+// registered unions are converted once into Definitions and referenced with $ref everywhere they are used.
+type Reflector struct {
+	refPrefix   string
+	definitions *orderedmap.OrderedMap[string, *JSONSchema]
+	names       map[reflect.Type]string
+	// inline reflectors only reference the types that contain themselves, and inline the rest.
+	inline   bool
+	visiting map[reflect.Type]struct{}
+}
+
+// NewReflector creates a Reflector whose references are refPrefix followed by the definition name,
+// e.g. "#/components/schemas/".
+func NewReflector(refPrefix string) *Reflector {
+	return &Reflector{
+		refPrefix:   refPrefix,
+		definitions: orderedmap.New[string, *JSONSchema](),
+		names:       map[reflect.Type]string{},
+	}
+}
+
+// newInlineReflector creates the Reflector used by the package level functions, which collects recursive types
+// into the $defs of the returned schema.
+func newInlineReflector() *Reflector {
+	return &Reflector{
+		refPrefix:   "#/$defs/",
+		definitions: orderedmap.New[string, *JSONSchema](),
+		names:       map[reflect.Type]string{},
+		inline:      true,
+		visiting:    map[reflect.Type]struct{}{},
+	}
+}
+
+// withDefinitions attaches the definitions collected by an inline reflector to schema.
+func (r *Reflector) withDefinitions(schema *JSONSchema) *JSONSchema {
+	if r.definitions.Len() > 0 {
+		schema.Defs = r.definitions
+	}
+
+	return schema
+}
+
+// Definitions returns the schemas of the referenced types by name, in the order they were first used.
+func (r *Reflector) Definitions() *orderedmap.OrderedMap[string, *JSONSchema] {
+	return r.definitions
+}
+
+func (r *Reflector) convertType(value reflect.Type) (*JSONSchema, error) {
+	if schema, found := customSchema(value); found {
+		return schema, nil
+	}
+
+	if isOptional(value) {
+		schema, err := r.convertType(value.Field(0).Type)
+		if err != nil {
+			return nil, err
+		}
+
+		schema.Nullable = true
+
+		return schema, nil
+	}
+
+	if _, isUnion := lookupUnion(value); !isUnion &&
+		(value.Kind() != reflect.Struct || value.Name() == "" || value == reflect.TypeOf(time.Time{})) {
+		return r.convertInline(value)
+	}
+
+	if r.inline {
+		return r.inlineRecursive(value)
+	}
+
+	return r.reference(value)
+}
+
+// inlineRecursive inlines value, unless value is already being converted further up, in which case it is moved
+// into the definitions and referenced from both places.
+func (r *Reflector) inlineRecursive(value reflect.Type) (*JSONSchema, error) {
+	if name, found := r.names[value]; found {
+		//nolint:exhaustruct
+		return &JSONSchema{Ref: r.refPrefix + name}, nil
+	}
+
+	if _, found := r.visiting[value]; found {
+		name := r.definitionName(value)
+		r.names[value] = name
+		//nolint:exhaustruct
+		r.definitions.Set(name, &JSONSchema{})
+
+		//nolint:exhaustruct
+		return &JSONSchema{Ref: r.refPrefix + name}, nil
+	}
+
+	r.visiting[value] = struct{}{}
+	defer delete(r.visiting, value)
+
+	schema, err := r.convertInline(value)
+	if err != nil {
+		return nil, err
+	}
+
+	name, found := r.names[value]
+	if !found {
+		return schema, nil
+	}
+
+	definition, _ := r.definitions.Get(name)
+	*definition = *schema
+
+	//nolint:exhaustruct
+	return &JSONSchema{Ref: r.refPrefix + name}, nil
+}
+
+func (r *Reflector) reference(value reflect.Type) (*JSONSchema, error) {
+	if name, found := r.names[value]; found {
+		//nolint:exhaustruct
+		return &JSONSchema{Ref: r.refPrefix + name}, nil
+	}
+
+	name := r.definitionName(value)
+
+	// register the definition before converting it, so recursive types reference it instead of recursing.
+	//nolint:exhaustruct
+	definition := &JSONSchema{}
+	r.names[value] = name
+	r.definitions.Set(name, definition)
+
+	schema, err := r.convertInline(value)
+	if err != nil {
+		delete(r.names, value)
+		r.definitions.Delete(name)
+
+		return nil, err
+	}
+
+	*definition = *schema
+
+	//nolint:exhaustruct
+	return &JSONSchema{Ref: r.refPrefix + name}, nil
+}
+
+//nolint:gochecknoglobals
+var (
+	qualifierRegex    = regexp.MustCompile(`[\w./-]*\.`)
+	nonIdentRegex     = regexp.MustCompile(`[^A-Za-z0-9_]`)
+	pathSeparateRegex = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+// definitionName returns the type name, prefixed with as many package path elements as needed to make it unique,
+// so that the first type keeps its plain name and colliding types from other packages get qualified names.
+func (r *Reflector) definitionName(value reflect.Type) string {
+	base := nonIdentRegex.ReplaceAllString(qualifierRegex.ReplaceAllString(value.Name(), ""), "")
+
+	name := base
+	pkgPath := strings.Split(value.PkgPath(), "/")
+
+	for idx := len(pkgPath) - 1; r.nameTaken(name) && idx >= 0; idx-- {
+		prefix := ""
+
+		for _, word := range pathSeparateRegex.Split(pkgPath[idx], -1) {
+			if word != "" {
+				prefix += strings.ToUpper(word[:1]) + word[1:]
+			}
+		}
+
+		name = prefix + name
+	}
+
+	for idx := 2; r.nameTaken(name); idx++ {
+		name = base + strconv.Itoa(idx)
+	}
+
+	return name
+}
+
+func (r *Reflector) nameTaken(name string) bool {
+	_, found := r.definitions.Get(name)
+
+	return found
+}
@@ -0,0 +1,168 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/DimmyJing/valise/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TestTreeNode struct {
+	Name     string         `json:"name"`
+	Children []TestTreeNode `json:"children"`
+}
+
+type ValidationError struct {
+	Name string `json:"name"`
+}
+
+type TestReflectorInput struct {
+	Root   TestTreeNode               `json:"root"`
+	Other  *TestTreeNode              `json:"other"`
+	Error  jsonschema.ValidationError `json:"error"`
+	Error2 ValidationError            `json:"error2"`
+}
+
+func TestReflector(t *testing.T) {
+	t.Parallel()
+
+	reflector := jsonschema.NewReflector("#/components/schemas/")
+
+	schema, err := reflector.RequestBodyToSchema(reflect.TypeOf(TestReflectorInput{})) //nolint:exhaustruct
+	require.NoError(t, err)
+
+	root, _ := schema.Properties.Get("root")
+	assert.Equal(t, "#/components/schemas/TestTreeNode", root.Ref)
+
+	other, _ := schema.Properties.Get("other")
+	assert.Equal(t, "#/components/schemas/TestTreeNode", other.Ref)
+
+	definitions := reflector.Definitions()
+
+	names := []string{}
+	for pair := definitions.Oldest(); pair != nil; pair = pair.Next() {
+		names = append(names, pair.Key)
+	}
+
+	assert.Equal(t, []string{"TestTreeNode", "ValidationError", "JsonschemaTestValidationError"}, names)
+
+	node, _ := definitions.Get("TestTreeNode")
+	children, _ := node.Properties.Get("children")
+	assert.Equal(t, "#/components/schemas/TestTreeNode", children.Items.Ref)
+
+	nodeJSON, err := json.Marshal(node)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"title": "TestTreeNode",
+		"type": "object",
+		"properties": {
+			"name": {"title": "name", "type": "string"},
+			"children": {"title": "children", "type": "array", "items": {"$ref": "#/components/schemas/TestTreeNode"}}
+		},
+		"required": ["name", "children"],
+		"additionalProperties": false
+	}`, string(nodeJSON))
+
+	ts, err := jsonschema.JSONSchemaToTS(node, "export type TestTreeNode = ")
+	require.NoError(t, err)
+	assert.Equal(t, "export type TestTreeNode = {\n  name: string;\n  children: TestTreeNode[];\n}", ts)
+
+	inline, err := jsonschema.AnyToSchema(reflect.TypeOf(TestReflectorInput{}.Error2))
+	require.NoError(t, err)
+	assert.Empty(t, inline.Ref)
+}
+
+type TestReply struct {
+	Text   string     `json:"text"`
+	Parent *TestReply `json:"parent,omitempty"`
+}
+
+type TestThread struct {
+	Title    string       `json:"title"`
+	Comments []TestReply  `json:"comments"`
+	Root     TestTreeNode `json:"root"`
+}
+
+func TestInlineRecursive(t *testing.T) {
+	t.Parallel()
+
+	schema, err := jsonschema.AnyToSchema(reflect.TypeOf(TestThread{})) //nolint:exhaustruct
+	require.NoError(t, err)
+
+	comments, _ := schema.Properties.Get("comments")
+	assert.Equal(t, "#/$defs/TestReply", comments.Items.Ref)
+
+	root, _ := schema.Properties.Get("root")
+	assert.Equal(t, "#/$defs/TestTreeNode", root.Ref)
+
+	require.NotNil(t, schema.Defs)
+	assert.Equal(t, 2, schema.Defs.Len())
+
+	comment, _ := schema.Defs.Get("TestReply")
+	parent, _ := comment.Properties.Get("parent")
+	assert.Equal(t, "#/$defs/TestReply", parent.Ref)
+
+	ts, err := jsonschema.JSONSchemaToTS(schema, "export type TestThread = ")
+	require.NoError(t, err)
+	assert.Equal(t, `export type TestReply = {
+  text: string;
+  parent?: TestReply | null;
+}
+
+export type TestTreeNode = {
+  name: string;
+  children: TestTreeNode[];
+}
+
+export type TestThread = {
+  title: string;
+  comments: TestReply[];
+  root: TestTreeNode;
+}`, ts)
+
+	flat, err := jsonschema.AnyToSchema(reflect.TypeOf(TestReflectorInput{}.Error2))
+	require.NoError(t, err)
+	assert.Nil(t, flat.Defs)
+
+	params, err := jsonschema.ParametersToSchema(reflect.TypeOf(TestThread{}), true) //nolint:exhaustruct
+	require.NoError(t, err)
+	assert.Equal(t, "#/$defs/TestTreeNode", params[2].Schema.Ref)
+	assert.NotNil(t, params[2].Schema.Defs)
+}
+
+func TestRecursiveValue(t *testing.T) {
+	t.Parallel()
+
+	tree := TestTreeNode{Name: "a", Children: []TestTreeNode{{Name: "b", Children: []TestTreeNode{}}}}
+
+	anyVal, err := jsonschema.ValueToAny(reflect.ValueOf(tree))
+	require.NoError(t, err)
+
+	var decoded TestTreeNode
+
+	require.NoError(t, jsonschema.AnyToValue(anyVal, reflect.ValueOf(&decoded).Elem()))
+	assert.Equal(t, tree, decoded)
+
+	parent := &TestReply{Text: "parent", Parent: nil}
+	child := TestReply{Text: "child", Parent: parent}
+
+	anyVal, err = jsonschema.ValueToAny(reflect.ValueOf(child))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"text": "child", "parent": map[string]any{"text": "parent"}}, anyVal)
+
+	var decodedComment TestReply
+
+	require.NoError(t, jsonschema.AnyToValue(anyVal, reflect.ValueOf(&decodedComment).Elem()))
+	assert.Equal(t, child, decodedComment)
+
+	shared := &TestReply{Text: "shared", Parent: nil}
+	_, err = jsonschema.ValueToAny(reflect.ValueOf([]TestReply{{Text: "a", Parent: shared}, {Text: "b", Parent: shared}}))
+	require.NoError(t, err)
+
+	parent.Parent = parent
+	_, err = jsonschema.ValueToAny(reflect.ValueOf(child))
+	require.Error(t, err)
+}
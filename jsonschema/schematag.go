@@ -0,0 +1,125 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errInvalidSchemaTag = errors.New("invalid schema tag")
+
+//nolint:gochecknoglobals
+var schemaOptions = append(slices.Clone(validateOptions),
+	"exclusiveMin", "exclusiveMax", "multipleOf", "uniqueItems",
+	"default", "example", "const", "deprecated", "readOnly", "writeOnly",
+)
+
+// applySchemaTag applies the schema tag of field, e.g. `schema:"min=0,max=10,default=5,example=3"`. It accepts the
+// options of the validate tag, which are enforced the same way, and the annotations that only document the field.
+func applySchemaTag(schema *JSONSchema, typ reflect.Type, field reflect.StructField) error { //nolint:cyclop,funlen
+	tag, found := field.Tag.Lookup("schema")
+	if !found || tag == "" {
+		return nil
+	}
+
+	for _, option := range splitTagOptions(tag, schemaOptions) {
+		key, value, _ := strings.Cut(option, "=")
+
+		switch key {
+		case "exclusiveMin", "exclusiveMax", "multipleOf":
+			if !isNumberKind(typ.Kind()) {
+				return fmt.Errorf("%s on non-numeric type %s: %w", key, typ.String(), errInvalidSchemaTag)
+			}
+
+			bound, err := strconv.ParseFloat(value, 64)
+			if err != nil || (key == "multipleOf" && bound <= 0) {
+				return fmt.Errorf("invalid %s %s: %w", key, value, errInvalidSchemaTag)
+			}
+
+			switch key {
+			case "exclusiveMin":
+				schema.ExclusiveMinimum = &bound
+			case "exclusiveMax":
+				schema.ExclusiveMaximum = &bound
+			default:
+				schema.MultipleOf = &bound
+			}
+		case "uniqueItems":
+			if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array {
+				return fmt.Errorf("uniqueItems on non-list type %s: %w", typ.String(), errInvalidSchemaTag)
+			}
+
+			schema.UniqueItems = true
+		case "default", "example", "const":
+			parsed, err := parseTagValue(typ, value)
+			if err != nil {
+				return fmt.Errorf("invalid %s %s: %w", key, value, errors.Join(err, errInvalidSchemaTag))
+			}
+
+			switch key {
+			case "default":
+				schema.Default = parsed
+			case "example":
+				schema.Examples = append(schema.Examples, parsed)
+			default:
+				schema.Const = parsed
+			}
+		case "deprecated", "readOnly", "writeOnly":
+			flag := true
+
+			if value != "" {
+				var err error
+				if flag, err = strconv.ParseBool(value); err != nil {
+					return fmt.Errorf("invalid %s %s: %w", key, value, errInvalidSchemaTag)
+				}
+			}
+
+			switch key {
+			case "deprecated":
+				schema.Deprecated = flag
+			case "readOnly":
+				schema.ReadOnly = flag
+			default:
+				schema.WriteOnly = flag
+			}
+		default:
+			if err := applyValidateOption(schema, typ, key, value); err != nil {
+				return errors.Join(err, errInvalidSchemaTag)
+			}
+		}
+	}
+
+	return nil
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	//nolint:exhaustive
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseTagValue parses a value of a schema tag as json, except for strings, times and text types which are taken as
+// is.
+func parseTagValue(typ reflect.Type, value string) (any, error) {
+	if typ.Kind() == reflect.String || typ == reflect.TypeOf(time.Time{}) || IsTextType(typ) {
+		return value, nil
+	}
+
+	var parsed any
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse %s as json: %w", value, err)
+	}
+
+	return parsed, nil
+}
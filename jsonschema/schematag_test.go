@@ -0,0 +1,85 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/DimmyJing/valise/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSchemaTag struct {
+	Quantity int      `json:"quantity" schema:"min=0,max=10,multipleOf=2,default=4,example=2,example=6"`
+	Price    float64  `json:"price"    schema:"exclusiveMin=0,exclusiveMax=1000"`
+	Code     string   `json:"code"     schema:"pattern=^[A-Z]{2,3}$,example=AB,deprecated"`
+	Kind     string   `json:"kind"     schema:"const=widget,readOnly"`
+	Secret   string   `json:"secret"   schema:"writeOnly,min=8"`
+	Tags     []string `json:"tags"     schema:"uniqueItems,max=3,default=[\"a\",\"b\"]"`
+}
+
+func TestSchemaTag(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	schema, err := jsonschema.AnyToSchema(reflect.TypeOf(testSchemaTag{})) //nolint:exhaustruct
+	require.NoError(t, err)
+
+	propertiesJSON, err := json.Marshal(schema.Properties)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"quantity": {
+			"title": "quantity", "type": "integer", "format": "int32",
+			"default": 4, "examples": [2, 6], "minimum": 0, "maximum": 10, "multipleOf": 2
+		},
+		"price": {
+			"title": "price", "type": "number", "format": "double", "exclusiveMinimum": 0, "exclusiveMaximum": 1000
+		},
+		"code": {"title": "code", "type": "string", "examples": ["AB"], "deprecated": true, "pattern": "^[A-Z]{2,3}$"},
+		"kind": {"title": "kind", "type": "string", "const": "widget", "readOnly": true},
+		"secret": {"title": "secret", "type": "string", "writeOnly": true, "minLength": 8},
+		"tags": {
+			"title": "tags", "type": "array", "items": {"title": "string", "type": "string"},
+			"default": ["a", "b"], "maxItems": 3, "uniqueItems": true
+		}
+	}`, string(propertiesJSON))
+
+	ts, err := jsonschema.JSONSchemaToTS(schema, "export type SchemaTag = ")
+	require.NoError(t, err)
+	assert.Contains(t, ts, "  /** @default 4 */\n  quantity: number;\n")
+	assert.Contains(t, ts, "  /** @deprecated */\n  code: string;\n")
+
+	valid := testSchemaTag{Quantity: 4, Price: 1, Code: "AB", Kind: "widget", Secret: "12345678", Tags: []string{"a"}}
+	assert.Empty(t, jsonschema.ValidateValue(reflect.ValueOf(valid)))
+
+	invalid := testSchemaTag{Quantity: 3, Price: 0, Code: "ab", Kind: "gadget", Secret: "1", Tags: []string{"a", "a"}}
+	assert.Equal(t, []jsonschema.ValidationError{
+		{Path: "/quantity", Message: "must be a multiple of 2"},
+		{Path: "/price", Message: "must be greater than 0"},
+		{Path: "/code", Message: "must match pattern ^[A-Z]{2,3}$"},
+		{Path: "/kind", Message: "must be widget"},
+		{Path: "/secret", Message: "length must be at least 8"},
+		{Path: "/tags", Message: "must have unique items"},
+	}, jsonschema.ValidateValue(reflect.ValueOf(invalid)))
+
+	for _, typ := range []reflect.Type{
+		reflect.TypeOf(struct {
+			Name string `schema:"multipleOf=2"`
+		}{Name: ""}),
+		reflect.TypeOf(struct {
+			Count int `schema:"default=abc"`
+		}{Count: 0}),
+		reflect.TypeOf(struct {
+			Count int `schema:"uniqueItems"`
+		}{Count: 0}),
+		reflect.TypeOf(struct {
+			Count int `schema:"deprecated=maybe"`
+		}{Count: 0}),
+		reflect.TypeOf(struct {
+			Count int `schema:"unknown=1"`
+		}{Count: 0}),
+	} {
+		_, err = jsonschema.AnyToSchema(typ)
+		require.Error(t, err, typ.String())
+	}
+}
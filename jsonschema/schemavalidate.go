@@ -0,0 +1,279 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// Validate checks value against the schema and reports the violations with json pointer paths. The value is
+// anything ValueToAny accepts, e.g. a map[string]any decoded from json or a json.RawMessage. A $ref is resolved
+// against the $defs and definitions of s.
+func (s *JSONSchema) Validate(value any) []ValidationError {
+	return s.ValidateWithDefinitions(value, nil)
+}
+
+// ValidateWithDefinitions is Validate for schemas whose refs point outside of them, such as the schemas of a
+// Reflector with a refPrefix of "#/components/schemas/". Those refs are resolved by their last segment against
+// definitions, e.g. the Definitions of the Reflector.
+func (s *JSONSchema) ValidateWithDefinitions(
+	value any,
+	definitions *orderedmap.OrderedMap[string, *JSONSchema],
+) []ValidationError {
+	instance, err := jsonInstance(value)
+	if err != nil {
+		return []ValidationError{{Path: "", Message: err.Error()}}
+	}
+
+	return schemaValidator{root: s, definitions: definitions}.validate(s, instance, "", nil)
+}
+
+// jsonInstance converts value to what decoding its json into an any gives, so that it is checked as it would be sent.
+func jsonInstance(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	anyVal, err := ValueToAny(reflect.ValueOf(value))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert value: %w", err)
+	}
+
+	encoded, err := json.Marshal(anyVal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode value: %w", err)
+	}
+
+	var instance any
+	if err := json.Unmarshal(encoded, &instance); err != nil {
+		return nil, fmt.Errorf("failed to decode value: %w", err)
+	}
+
+	return instance, nil
+}
+
+type schemaValidator struct {
+	root        *JSONSchema
+	definitions *orderedmap.OrderedMap[string, *JSONSchema]
+}
+
+func (v schemaValidator) resolve(ref string) (*JSONSchema, bool) {
+	var defs *orderedmap.OrderedMap[string, *JSONSchema]
+
+	switch {
+	case ref == "#":
+		return v.root, true
+	case strings.HasPrefix(ref, "#/$defs/"):
+		defs = v.root.Defs
+	case strings.HasPrefix(ref, "#/definitions/"):
+		defs = v.root.Definitions
+	default:
+		defs = v.definitions
+	}
+
+	if defs == nil {
+		return nil, false
+	}
+
+	return defs.Get(RefName(ref))
+}
+
+// deref follows the refs of schema to the schema they point at.
+func (v schemaValidator) deref(schema *JSONSchema) (*JSONSchema, bool) {
+	seen := []string{}
+
+	for schema.Ref != "" {
+		if slices.Contains(seen, schema.Ref) {
+			return nil, false
+		}
+
+		seen = append(seen, schema.Ref)
+
+		resolved, found := v.resolve(schema.Ref)
+		if !found {
+			return nil, false
+		}
+
+		schema = resolved
+	}
+
+	return schema, true
+}
+
+func (v schemaValidator) validate( //nolint:cyclop,funlen,gocognit
+	schema *JSONSchema,
+	instance any,
+	path string,
+	errs []ValidationError,
+) []ValidationError {
+	fail := func(path string, format string, args ...any) {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if schema.boolean != nil {
+		if !*schema.boolean {
+			fail(path, "is not allowed")
+		}
+
+		return errs
+	}
+
+	if instance == nil && schema.Nullable {
+		return errs
+	}
+
+	if schema.Ref != "" {
+		resolved, found := v.resolve(schema.Ref)
+		if !found {
+			fail(path, "unknown ref %s", schema.Ref)
+
+			return errs
+		}
+
+		errs = v.validate(resolved, instance, path, errs)
+	}
+
+	if len(schema.AnyOf) > 0 && !slices.ContainsFunc(schema.AnyOf, func(member *JSONSchema) bool {
+		return len(v.validate(member, instance, path, nil)) == 0
+	}) {
+		fail(path, "must match a schema of anyOf")
+	}
+
+	if len(schema.OneOf) > 0 {
+		errs = v.validateOneOf(schema, instance, path, errs)
+	}
+
+	if schema.Type != "" && !matchesType(schema.Type, instance) {
+		fail(path, "must be %s", typeArticle(schema.Type))
+
+		return errs
+	}
+
+	if len(schema.Enums) > 0 {
+		if str, isString := instance.(string); !isString || !slices.Contains(schema.Enums, str) {
+			fail(path, "must be one of %s", strings.Join(schema.Enums, ", "))
+		}
+	}
+
+	if instance != nil {
+		errs = checkConstraints(schema, reflect.ValueOf(instance), path, errs)
+	}
+
+	switch instance := instance.(type) {
+	case []any:
+		if schema.Items != nil {
+			for idx, item := range instance {
+				errs = v.validate(schema.Items, item, fmt.Sprintf("%s/%d", path, idx), errs)
+			}
+		}
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, found := instance[name]; !found {
+				fail(path+"/"+escapePointer(name), "is required")
+			}
+		}
+
+		keys := make([]string, 0, len(instance))
+		for key := range instance {
+			keys = append(keys, key)
+		}
+
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			keyPath := path + "/" + escapePointer(key)
+
+			if schema.Properties != nil {
+				if property, found := schema.Properties.Get(key); found {
+					errs = v.validate(property, instance[key], keyPath, errs)
+
+					continue
+				}
+			}
+
+			if schema.AdditionalProperties != nil {
+				errs = v.validate(schema.AdditionalProperties, instance[key], keyPath, errs)
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateOneOf checks that instance matches exactly one member, which is picked by the discriminator if there is
+// one, so that the errors of that member are reported.
+func (v schemaValidator) validateOneOf(
+	schema *JSONSchema,
+	instance any,
+	path string,
+	errs []ValidationError,
+) []ValidationError {
+	if object, isObject := instance.(map[string]any); isObject && schema.Discriminator != nil {
+		propertyName := schema.Discriminator.PropertyName
+		propertyPath := path + "/" + escapePointer(propertyName)
+
+		for _, member := range schema.OneOf {
+			resolved, found := v.deref(member)
+			if !found || resolved.Properties == nil {
+				continue
+			}
+
+			if property, found := resolved.Properties.Get(propertyName); found && property.Const != nil &&
+				reflect.DeepEqual(property.Const, object[propertyName]) {
+				return v.validate(member, instance, path, errs)
+			}
+		}
+
+		return append(errs, ValidationError{Path: propertyPath, Message: fmt.Sprintf("unknown %s %v",
+			propertyName, object[propertyName])})
+	}
+
+	matches := 0
+
+	for _, member := range schema.OneOf {
+		if len(v.validate(member, instance, path, nil)) == 0 {
+			matches++
+		}
+	}
+
+	if matches != 1 {
+		errs = append(errs, ValidationError{Path: path, Message: "must match exactly one schema of oneOf"})
+	}
+
+	return errs
+}
+
+func matchesType(typ string, instance any) bool {
+	switch instance := instance.(type) {
+	case nil:
+		return typ == "null"
+	case bool:
+		return typ == "boolean"
+	case float64:
+		return typ == "number" || (typ == "integer" && instance == math.Trunc(instance))
+	case string:
+		return typ == "string"
+	case []any:
+		return typ == "array"
+	case map[string]any:
+		return typ == "object"
+	default:
+		return false
+	}
+}
+
+func typeArticle(typ string) string {
+	switch typ {
+	case "integer", "array", "object":
+		return "an " + typ
+	case "null":
+		return typ
+	default:
+		return "a " + typ
+	}
+}
@@ -0,0 +1,162 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/DimmyJing/valise/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+func TestSchemaValidate(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	var schema jsonschema.JSONSchema
+
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["id", "email", "tags"],
+		"additionalProperties": false,
+		"properties": {
+			"id": {"type": "string", "format": "uuid"},
+			"email": {"type": "string", "format": "email"},
+			"plan": {"type": "string", "enum": ["free", "pro"]},
+			"seats": {"type": "integer", "minimum": 1, "multipleOf": 5},
+			"since": {"type": ["string", "null"], "format": "date-time"},
+			"homepage": {"type": "string", "format": "uri"},
+			"tags": {"type": "array", "minItems": 1, "maxItems": 2, "uniqueItems": true, "items": {"type": "string"}},
+			"owner": {"$ref": "#/$defs/Owner"}
+		},
+		"$defs": {
+			"Owner": {
+				"type": "object",
+				"required": ["name"],
+				"properties": {"name": {"type": "string", "minLength": 1}},
+				"additionalProperties": {"type": "integer"}
+			}
+		}
+	}`), &schema))
+
+	assert.Empty(t, schema.Validate(map[string]any{
+		"id":       "123e4567-e89b-12d3-a456-426614174000",
+		"email":    "a@b.co",
+		"plan":     "pro",
+		"seats":    10,
+		"since":    nil,
+		"homepage": "https://example.com",
+		"tags":     []string{"a"},
+		"owner":    map[string]any{"name": "x", "age": 3},
+	}))
+
+	assert.Empty(t, schema.Validate(json.RawMessage(
+		`{"id":"123e4567-e89b-12d3-a456-426614174000","email":"a@b.co","tags":["a"],"since":"2024-01-01T00:00:00Z"}`,
+	)))
+
+	assert.Equal(t, []jsonschema.ValidationError{
+		{Path: "/email", Message: "must be a valid email"},
+		{Path: "/homepage", Message: "must be a valid uri"},
+		{Path: "/id", Message: "must be a string"},
+		{Path: "/owner/name", Message: "is required"},
+		{Path: "/owner/age", Message: "must be an integer"},
+		{Path: "/plan", Message: "must be one of free, pro"},
+		{Path: "/seats", Message: "must be at least 1"},
+		{Path: "/seats", Message: "must be a multiple of 5"},
+		{Path: "/since", Message: "must be a valid date-time"},
+		{Path: "/tags", Message: "must have at most 2 items"},
+		{Path: "/tags", Message: "must have unique items"},
+		{Path: "/tags/0", Message: "must be a string"},
+		{Path: "/tags/1", Message: "must be a string"},
+		{Path: "/tags/2", Message: "must be a string"},
+		{Path: "/unknown", Message: "is not allowed"},
+	}, schema.Validate(map[string]any{
+		"id":       1,
+		"email":    "nope",
+		"plan":     "team",
+		"seats":    -2,
+		"since":    "yesterday",
+		"homepage": "example.com",
+		"tags":     []any{1, 1, 1},
+		"owner":    map[string]any{"age": 1.5},
+		"unknown":  true,
+	}))
+
+	assert.Equal(t, []jsonschema.ValidationError{{Path: "", Message: "must be an object"}}, schema.Validate(nil))
+}
+
+func TestSchemaValidateUnion(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, jsonschema.RegisterUnion("kind", map[string]TestShape{
+		"circle": TestCircle{Radius: 0},
+		"square": &TestSquare{Side: 0},
+	}))
+
+	schema, err := jsonschema.AnyToSchema(reflect.TypeOf(TestDrawing{})) //nolint:exhaustruct
+	require.NoError(t, err)
+
+	assert.Empty(t, schema.Validate(TestDrawing{Main: TestCircle{Radius: 1}, Shapes: []TestShape{&TestSquare{Side: 2}}}))
+
+	assert.Equal(t, []jsonschema.ValidationError{
+		{Path: "/main/radius", Message: "is required"},
+		{Path: "/main/side", Message: "is not allowed"},
+		{Path: "/shapes/0/kind", Message: "unknown kind triangle"},
+	}, schema.Validate(map[string]any{
+		"main":   map[string]any{"kind": "circle", "side": 1},
+		"shapes": []any{map[string]any{"kind": "triangle"}},
+	}))
+}
+
+func TestSchemaValidateDefinitions(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, jsonschema.RegisterUnion("kind", map[string]TestShape{
+		"circle": TestCircle{Radius: 0},
+		"square": &TestSquare{Side: 0},
+	}))
+
+	reflector := jsonschema.NewReflector("#/components/schemas/")
+
+	schema, err := reflector.AnyToSchema(reflect.TypeOf(TestDrawing{})) //nolint:exhaustruct
+	require.NoError(t, err)
+
+	drawing := TestDrawing{Main: TestCircle{Radius: 1}, Shapes: []TestShape{&TestSquare{Side: 2}}}
+	assert.Empty(t, schema.ValidateWithDefinitions(drawing, reflector.Definitions()))
+	assert.Equal(t, []jsonschema.ValidationError{
+		{Path: "", Message: "unknown ref #/components/schemas/TestDrawing"},
+	}, schema.Validate(drawing))
+
+	var union jsonschema.JSONSchema
+
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"oneOf": [{"$ref": "#/components/schemas/Circle"}, {"$ref": "#/components/schemas/Square"}],
+		"discriminator": {"propertyName": "kind"}
+	}`), &union))
+
+	var definitions *orderedmap.OrderedMap[string, *jsonschema.JSONSchema]
+
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"Circle": {
+			"type": "object",
+			"required": ["kind", "radius"],
+			"properties": {"kind": {"const": "circle"}, "radius": {"type": "number"}}
+		},
+		"Square": {"$ref": "#/components/schemas/SquareShape"},
+		"SquareShape": {
+			"type": "object",
+			"required": ["kind", "side"],
+			"properties": {"kind": {"const": "square"}, "side": {"type": "number"}}
+		}
+	}`), &definitions))
+
+	assert.Empty(t, union.ValidateWithDefinitions(map[string]any{"kind": "circle", "radius": 1}, definitions))
+	assert.Empty(t, union.ValidateWithDefinitions(map[string]any{"kind": "square", "side": 1}, definitions))
+	assert.Equal(t, []jsonschema.ValidationError{
+		{Path: "/side", Message: "is required"},
+	}, union.ValidateWithDefinitions(map[string]any{"kind": "square", "radius": 1}, definitions))
+	assert.Equal(t, []jsonschema.ValidationError{
+		{Path: "/kind", Message: "unknown kind triangle"},
+	}, union.ValidateWithDefinitions(map[string]any{"kind": "triangle"}, definitions))
+}
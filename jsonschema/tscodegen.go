@@ -1,9 +1,12 @@
 package jsonschema
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -13,7 +16,41 @@ func JSONSchemaToTS(inp *JSONSchema, prefix string) (string, error) {
 		return "", fmt.Errorf("failed to convert json schema to ts: %w", err)
 	}
 
-	return FormatComment(inp.Description) + prefix + types, nil
+	var defs strings.Builder
+
+	if inp.Defs != nil {
+		for pair := inp.Defs.Oldest(); pair != nil; pair = pair.Next() {
+			def, err := jsonSchemaToTS(*pair.Value)
+			if err != nil {
+				return "", fmt.Errorf("failed to convert definition %s to ts: %w", pair.Key, err)
+			}
+
+			defs.WriteString(schemaComment(pair.Value) + "export type " + pair.Key + " = " + def + "\n\n")
+		}
+	}
+
+	return defs.String() + schemaComment(inp) + prefix + types, nil
+}
+
+// schemaComment formats the description of schema along with its deprecated and default annotations.
+func schemaComment(schema *JSONSchema) string {
+	lines := []string{}
+
+	if schema.Description != "" {
+		lines = append(lines, schema.Description)
+	}
+
+	if schema.Default != nil {
+		if encoded, err := json.Marshal(schema.Default); err == nil {
+			lines = append(lines, "@default "+string(encoded))
+		}
+	}
+
+	if schema.Deprecated {
+		lines = append(lines, "@deprecated")
+	}
+
+	return FormatComment(strings.Join(lines, "\n"))
 }
 
 var errInvalidSchema = errors.New("invalid schema")
@@ -42,6 +79,17 @@ func FormatComment(comment string) string {
 	return builder.String()
 }
 
+//nolint:gochecknoglobals
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func formatKey(key string) string {
+	if identifierRegex.MatchString(key) {
+		return key
+	}
+
+	return strconv.Quote(key)
+}
+
 func indentMiddle(input string) string {
 	inputSplit := strings.Split(input, "\n")
 
@@ -61,7 +109,51 @@ func indentMiddle(input string) string {
 	return builder.String()
 }
 
+// RefName returns the type name a $ref points to, e.g. "ErrorMessage" for "#/components/schemas/ErrorMessage".
+func RefName(ref string) string {
+	return ref[strings.LastIndexByte(ref, '/')+1:]
+}
+
 func jsonSchemaToTS(input JSONSchema) (string, error) { //nolint:funlen,cyclop,gocognit
+	if input.Nullable {
+		input.Nullable = false
+
+		res, err := jsonSchemaToTS(input)
+		if err != nil {
+			return "", err
+		}
+
+		return res + " | null", nil
+	}
+
+	if len(input.AnyOf) > 0 || len(input.OneOf) > 0 {
+		members := make([]string, 0, len(input.AnyOf)+len(input.OneOf))
+
+		for _, member := range append(slices.Clone(input.AnyOf), input.OneOf...) {
+			res, err := jsonSchemaToTS(*member)
+			if err != nil {
+				return "", fmt.Errorf("failed to convert union member: %w", err)
+			}
+
+			members = append(members, res)
+		}
+
+		return strings.Join(members, " | "), nil
+	}
+
+	if input.Ref != "" {
+		return RefName(input.Ref), nil
+	}
+
+	if input.Const != nil {
+		literal, err := json.Marshal(input.Const)
+		if err != nil {
+			return "", fmt.Errorf("failed to convert const: %w", err)
+		}
+
+		return string(literal), nil
+	}
+
 	if input.Type == "" {
 		return "unknown", nil
 	}
@@ -90,6 +182,10 @@ func jsonSchemaToTS(input JSONSchema) (string, error) { //nolint:funlen,cyclop,g
 			return "", fmt.Errorf("failed to convert array items: %w", err)
 		}
 
+		if strings.Contains(res, " | ") {
+			res = "(" + res + ")"
+		}
+
 		return res + "[]", nil
 	case "null":
 		return "null", nil
@@ -122,8 +218,8 @@ func jsonSchemaToTS(input JSONSchema) (string, error) { //nolint:funlen,cyclop,g
 					return "", fmt.Errorf("failed to convert object properties: %w", err)
 				}
 
-				insideBuilder.WriteString(FormatComment(value.Description))
-				insideBuilder.WriteString(fmt.Sprintf("%s%s: %s;\n", key, optional, res))
+				insideBuilder.WriteString(schemaComment(value))
+				insideBuilder.WriteString(fmt.Sprintf("%s%s: %s;\n", formatKey(key), optional, res))
 			}
 
 			insideBuilder.WriteString("}")
@@ -68,6 +68,9 @@ func TestSchemaConvert(t *testing.T) { //nolint:funlen
 		AdditionalProperties: &jsonschema.JSONSchemaFalse,
 		Properties:           orderedmap.New[string, *jsonschema.JSONSchema](),
 	})
+	schema.Properties.Set("X-Test12", &jsonschema.JSONSchema{
+		Type: "string",
+	})
 
 	schema.Required = []string{"test1"}
 
@@ -86,6 +89,7 @@ func TestSchemaConvert(t *testing.T) { //nolint:funlen
   test9?: Record<string, any>;
   test10?: Record<string, string>;
   test11?: Record<string, never>;
+  "X-Test12"?: string;
 }`
 	assert.Equal(t, expected, res)
 }
@@ -0,0 +1,234 @@
+package jsonschema
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+type Discriminator struct {
+	PropertyName string `json:"propertyName"`
+}
+
+type unionMember struct {
+	value string
+	typ   reflect.Type
+}
+
+type union struct {
+	discriminator string
+	members       []unionMember
+}
+
+func (u *union) memberByValue(value string) (reflect.Type, bool) {
+	for _, member := range u.members {
+		if member.value == value {
+			return member.typ, true
+		}
+	}
+
+	return nil, false
+}
+
+// memberByType finds the member of typ, where a struct and a pointer to it are the same member, so that a member
+// registered as Circle{} is found for a *Circle too.
+func (u *union) memberByType(typ reflect.Type) (string, bool) {
+	for _, member := range u.members {
+		if memberStruct(member.typ) == memberStruct(typ) {
+			return member.value, true
+		}
+	}
+
+	return "", false
+}
+
+func memberStruct(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Pointer {
+		return typ.Elem()
+	}
+
+	return typ
+}
+
+//nolint:gochecknoglobals
+var (
+	unionRegistryMu sync.RWMutex
+	unionRegistry   = map[reflect.Type]*union{}
+)
+
+var errInvalidUnion = errors.New("invalid union")
+
+// RegisterUnion registers the implementations of the interface I, keyed by the value of their discriminator
+// property, e.g. RegisterUnion[Shape]("kind", map[string]Shape{"circle": Circle{}, "square": Square{}}).
+// Fields of type I then have a oneOf schema, and are encoded and decoded as objects that carry the discriminator.
+// The members must be structs or pointers to structs. Registering I again replaces its previous registration.
+func RegisterUnion[I any](discriminator string, members map[string]I) error {
+	unionType := reflect.TypeOf((*I)(nil)).Elem()
+	if unionType.Kind() != reflect.Interface {
+		return fmt.Errorf("%s is not an interface: %w", unionType, errInvalidUnion)
+	}
+
+	registered := &union{discriminator: discriminator, members: make([]unionMember, 0, len(members))}
+
+	for value, member := range members {
+		memberType := reflect.TypeOf(member)
+		if memberType == nil {
+			return fmt.Errorf("member %s of %s is nil: %w", value, unionType, errInvalidUnion)
+		}
+
+		if memberStruct(memberType).Kind() != reflect.Struct {
+			return fmt.Errorf("member %s of %s is not a struct: %w", value, unionType, errInvalidUnion)
+		}
+
+		if _, found := registered.memberByType(memberType); found {
+			return fmt.Errorf("member type %s of %s is registered twice: %w", memberType, unionType, errInvalidUnion)
+		}
+
+		registered.members = append(registered.members, unionMember{value: value, typ: memberType})
+	}
+
+	slices.SortFunc(registered.members, func(a, b unionMember) int {
+		return cmp.Compare(a.value, b.value)
+	})
+
+	unionRegistryMu.Lock()
+	defer unionRegistryMu.Unlock()
+
+	unionRegistry[unionType] = registered
+
+	return nil
+}
+
+func lookupUnion(typ reflect.Type) (*union, bool) {
+	if typ.Kind() != reflect.Interface {
+		return nil, false
+	}
+
+	unionRegistryMu.RLock()
+	defer unionRegistryMu.RUnlock()
+
+	registered, found := unionRegistry[typ]
+
+	return registered, found
+}
+
+// unionSchema converts the members of u to objects that require the discriminator property set to their value.
+func (r *Reflector) unionSchema(u *union) (*JSONSchema, error) {
+	//nolint:exhaustruct
+	schema := &JSONSchema{
+		OneOf:         make([]*JSONSchema, 0, len(u.members)),
+		Discriminator: &Discriminator{PropertyName: u.discriminator},
+	}
+
+	for _, member := range u.members {
+		memberSchema, err := r.convertInline(memberStruct(member.typ))
+		if err != nil {
+			return nil, fmt.Errorf("error converting union member %s: %w", member.value, err)
+		}
+
+		properties := orderedmap.New[string, *JSONSchema]()
+		//nolint:exhaustruct
+		properties.Set(u.discriminator, &JSONSchema{Title: u.discriminator, Type: "string", Const: member.value})
+
+		if memberSchema.Properties != nil {
+			for pair := memberSchema.Properties.Oldest(); pair != nil; pair = pair.Next() {
+				if pair.Key != u.discriminator {
+					properties.Set(pair.Key, pair.Value)
+				}
+			}
+		}
+
+		required := []string{u.discriminator}
+
+		for _, name := range memberSchema.Required {
+			if name != u.discriminator {
+				required = append(required, name)
+			}
+		}
+
+		memberSchema.Properties = properties
+		memberSchema.Required = required
+		schema.OneOf = append(schema.OneOf, memberSchema)
+	}
+
+	return schema, nil
+}
+
+var errUnionMember = errors.New("invalid union member")
+
+func (e encoder) unionToAny(u *union, value reflect.Value) (any, error) {
+	discriminatorValue, found := u.memberByType(value.Type())
+	if !found {
+		return nil, fmt.Errorf("unregistered member type %s: %w", value.Type(), errUnionMember)
+	}
+
+	anyVal, err := e.valueToAny(value)
+	if err != nil {
+		return nil, err
+	}
+
+	result, ok := anyVal.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("member type %s is not an object: %w", value.Type(), errUnionMember)
+	}
+
+	result[u.discriminator] = discriminatorValue
+
+	return result, nil
+}
+
+func (d decoder) decodeUnion(u *union, anyVal any, value reflect.Value, path string) error {
+	if anyVal == nil {
+		value.SetZero()
+
+		return nil
+	}
+
+	mapVal, isMapVal := anyVal.(map[string]any)
+	if !isMapVal {
+		return fmt.Errorf("invalid union value %v: %w", anyVal, errReflectType)
+	}
+
+	discriminatorValue, _ := mapVal[u.discriminator].(string)
+
+	memberType, found := u.memberByValue(discriminatorValue)
+	if !found {
+		return &DecodeError{
+			Path: path + "/" + escapePointer(u.discriminator),
+			Err:  fmt.Errorf("unknown %s %v: %w", u.discriminator, mapVal[u.discriminator], errUnionMember),
+		}
+	}
+
+	structType := memberStruct(memberType)
+
+	// the discriminator is only passed on to members that declare it themselves.
+	memberMap := make(map[string]any, len(mapVal))
+
+	for key, elem := range mapVal {
+		memberMap[key] = elem
+	}
+
+	if !slices.ContainsFunc(StructFields(structType), func(field StructField) bool {
+		return field.JSONName == u.discriminator
+	}) {
+		delete(memberMap, u.discriminator)
+	}
+
+	member := reflect.New(structType)
+	if err := d.decode(memberMap, member.Elem(), path); err != nil {
+		return err
+	}
+
+	if memberType.Kind() == reflect.Pointer {
+		value.Set(member)
+	} else {
+		value.Set(member.Elem())
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DimmyJing/valise/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TestShape interface {
+	Area() float64
+}
+
+type TestCircle struct {
+	Radius float64 `json:"radius"`
+}
+
+func (c TestCircle) Area() float64 {
+	return 3 * c.Radius * c.Radius
+}
+
+type TestSquare struct {
+	Side float64 `json:"side"`
+}
+
+func (s *TestSquare) Area() float64 {
+	return s.Side * s.Side
+}
+
+type TestDrawing struct {
+	Main   TestShape   `json:"main"`
+	Shapes []TestShape `json:"shapes"`
+}
+
+func TestUnion(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	require.NoError(t, jsonschema.RegisterUnion("kind", map[string]TestShape{
+		"circle": TestCircle{Radius: 0},
+		"square": &TestSquare{Side: 0},
+	}))
+
+	schema, err := jsonschema.AnyToSchema(reflect.TypeOf(TestDrawing{})) //nolint:exhaustruct
+	require.NoError(t, err)
+
+	main, _ := schema.Properties.Get("main")
+	mainJSON, err := json.Marshal(main)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"title": "main",
+		"oneOf": [
+			{
+				"title": "TestCircle",
+				"type": "object",
+				"properties": {
+					"kind": {"title": "kind", "type": "string", "const": "circle"},
+					"radius": {"title": "radius", "type": "number", "format": "double"}
+				},
+				"required": ["kind", "radius"],
+				"additionalProperties": false
+			},
+			{
+				"title": "TestSquare",
+				"type": "object",
+				"properties": {
+					"kind": {"title": "kind", "type": "string", "const": "square"},
+					"side": {"title": "side", "type": "number", "format": "double"}
+				},
+				"required": ["kind", "side"],
+				"additionalProperties": false
+			}
+		],
+		"discriminator": {"propertyName": "kind"}
+	}`, string(mainJSON))
+
+	ts, err := jsonschema.JSONSchemaToTS(schema, "export type TestDrawing = ")
+	require.NoError(t, err)
+	assert.Equal(t, `export type TestDrawing = {
+  main: {
+    kind: "circle";
+    radius: number;
+  } | {
+    kind: "square";
+    side: number;
+  };
+  shapes: ({
+    kind: "circle";
+    radius: number;
+  } | {
+    kind: "square";
+    side: number;
+  })[];
+}`, ts)
+
+	reflector := jsonschema.NewReflector("#/components/schemas/")
+	_, err = reflector.AnyToSchema(reflect.TypeOf(TestDrawing{})) //nolint:exhaustruct
+	require.NoError(t, err)
+
+	shape, found := reflector.Definitions().Get("TestShape")
+	require.True(t, found)
+	assert.Len(t, shape.OneOf, 2)
+
+	drawing := TestDrawing{Main: TestCircle{Radius: 1}, Shapes: []TestShape{&TestSquare{Side: 2}}}
+
+	anyVal, err := jsonschema.ValueToAny(reflect.ValueOf(drawing))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"main":   map[string]any{"kind": "circle", "radius": 1.0},
+		"shapes": []any{map[string]any{"kind": "square", "side": 2.0}},
+	}, anyVal)
+
+	var decoded TestDrawing
+
+	require.NoError(t, jsonschema.AnyToValue(anyVal, reflect.ValueOf(&decoded).Elem()))
+	assert.Equal(t, drawing, decoded)
+
+	err = jsonschema.AnyToValue(map[string]any{
+		"main":   map[string]any{"kind": "triangle"},
+		"shapes": []any{},
+	}, reflect.ValueOf(&decoded).Elem())
+
+	var decodeErr *jsonschema.DecodeError
+
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, "/main/kind", decodeErr.Path)
+
+	// a member is found whether it is registered and stored as a struct or a pointer to it.
+	anyVal, err = jsonschema.ValueToAny(reflect.ValueOf(TestDrawing{Main: &TestCircle{Radius: 1}, Shapes: nil}))
+	require.NoError(t, err)
+	//nolint:forcetypeassert
+	assert.Equal(t, map[string]any{"kind": "circle", "radius": 1.0}, anyVal.(map[string]any)["main"])
+
+	require.Error(t, jsonschema.RegisterUnion("kind", map[string]TestCircle{}))
+	require.Error(t, jsonschema.RegisterUnion("kind", map[string]TestShape{"circle": nil}))
+	require.Error(t, jsonschema.RegisterUnion("kind", map[string]TestShape{
+		"circle": TestCircle{Radius: 0},
+		"disc":   &TestCircle{Radius: 0},
+	}))
+}
+
+type TestFrame struct {
+	Shape *TestShape `json:"shape"`
+}
+
+func TestNullableUnion(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, jsonschema.RegisterUnion("kind", map[string]TestShape{
+		"circle": TestCircle{Radius: 0},
+		"square": &TestSquare{Side: 0},
+	}))
+
+	schema, err := jsonschema.AnyToSchema(reflect.TypeOf(TestFrame{})) //nolint:exhaustruct
+	require.NoError(t, err)
+
+	shape, _ := schema.Properties.Get("shape")
+	shapeJSON, err := json.Marshal(shape)
+	require.NoError(t, err)
+
+	var parsed struct {
+		Title string            `json:"title"`
+		OneOf json.RawMessage   `json:"oneOf"`
+		AnyOf []json.RawMessage `json:"anyOf"`
+	}
+
+	require.NoError(t, json.Unmarshal(shapeJSON, &parsed))
+	assert.Equal(t, "shape", parsed.Title)
+	assert.Nil(t, parsed.OneOf)
+	require.Len(t, parsed.AnyOf, 2)
+	assert.Contains(t, string(parsed.AnyOf[0]), `"discriminator":{"propertyName":"kind"}`)
+	assert.JSONEq(t, `{"type": "null"}`, string(parsed.AnyOf[1]))
+
+	// the document and the ts types agree that the union can be null.
+	var decoded jsonschema.JSONSchema
+
+	require.NoError(t, json.Unmarshal(shapeJSON, &decoded))
+	assert.Empty(t, decoded.Validate(nil))
+	assert.Empty(t, decoded.Validate(map[string]any{"kind": "circle", "radius": 1}))
+
+	ts, err := jsonschema.JSONSchemaToTS(shape, "")
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(ts, "| null"), ts)
+}
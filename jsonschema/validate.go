@@ -0,0 +1,392 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+
+	return e.Path + ": " + e.Message
+}
+
+var errInvalidValidateTag = errors.New("invalid validate tag")
+
+//nolint:gochecknoglobals
+var validateOptions = []string{"min", "max", "len", "pattern", "format"}
+
+// splitTagOptions splits a tag on commas, keeping commas that are part of a value (e.g. in a pattern).
+func splitTagOptions(tag string, options []string) []string {
+	result := []string{}
+
+	for _, part := range strings.Split(tag, ",") {
+		key, _, _ := strings.Cut(part, "=")
+		if len(result) > 0 && !slices.Contains(options, key) {
+			result[len(result)-1] += "," + part
+		} else {
+			result = append(result, part)
+		}
+	}
+
+	return result
+}
+
+// applyValidateTag applies the validate and schema tags of field to schema.
+func applyValidateTag(schema *JSONSchema, field reflect.StructField) error {
+	typ := field.Type
+	if isOptional(typ) {
+		typ = typ.Field(0).Type
+	}
+
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if tag, found := field.Tag.Lookup("validate"); found && tag != "" {
+		for _, option := range splitTagOptions(tag, validateOptions) {
+			key, value, _ := strings.Cut(option, "=")
+
+			if err := applyValidateOption(schema, typ, key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return applySchemaTag(schema, typ, field)
+}
+
+func applyValidateOption(schema *JSONSchema, typ reflect.Type, key string, value string) error {
+	switch key {
+	case "min", "max", "len":
+		if err := applyBound(schema, typ, key, value); err != nil {
+			return err
+		}
+	case "pattern":
+		if typ.Kind() != reflect.String {
+			return fmt.Errorf("pattern on non-string type %s: %w", typ.String(), errInvalidValidateTag)
+		}
+
+		if _, err := compilePattern(value); err != nil {
+			return fmt.Errorf("invalid pattern %s: %w", value, errors.Join(err, errInvalidValidateTag))
+		}
+
+		schema.Pattern = value
+	case "format":
+		if _, found := formatCheckers[value]; !found {
+			return fmt.Errorf("unknown format %s: %w", value, errInvalidValidateTag)
+		}
+
+		schema.Format = value
+	default:
+		return fmt.Errorf("unknown option %s: %w", key, errInvalidValidateTag)
+	}
+
+	return nil
+}
+
+func applyBound(schema *JSONSchema, typ reflect.Type, key string, value string) error { //nolint:cyclop
+	//nolint:exhaustive
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		bound, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid numeric bound %s: %w", value, errInvalidValidateTag)
+		}
+
+		switch key {
+		case "min":
+			schema.Minimum = &bound
+		case "max":
+			schema.Maximum = &bound
+		default:
+			return fmt.Errorf("len on numeric type %s: %w", typ.String(), errInvalidValidateTag)
+		}
+	case reflect.String, reflect.Slice, reflect.Array:
+		bound, err := strconv.Atoi(value)
+		if err != nil || bound < 0 {
+			return fmt.Errorf("invalid length bound %s: %w", value, errInvalidValidateTag)
+		}
+
+		minPtr, setMax := &schema.MinItems, schema.SetMaxItems
+		if typ.Kind() == reflect.String || typ.Elem().Kind() == reflect.Uint8 {
+			minPtr, setMax = &schema.MinLength, schema.SetMaxLength
+		}
+
+		if key == "min" || key == "len" {
+			*minPtr = bound
+		}
+
+		// a maximum of 0 is marked as a bound, so that max=0 and len=0 are enforced.
+		if key == "max" || key == "len" {
+			setMax(bound)
+		}
+	default:
+		return fmt.Errorf("%s on unsupported type %s: %w", key, typ.String(), errInvalidValidateTag)
+	}
+
+	return nil
+}
+
+type fieldConstraint struct {
+	schema *JSONSchema
+	err    error
+}
+
+//nolint:gochecknoglobals
+var constraintCache sync.Map
+
+func structConstraints(typ reflect.Type) []fieldConstraint {
+	if cached, found := constraintCache.Load(typ); found {
+		if constraints, ok := cached.([]fieldConstraint); ok {
+			return constraints
+		}
+	}
+
+	fields := StructFields(typ)
+	constraints := make([]fieldConstraint, len(fields))
+
+	for i, field := range fields {
+		_, hasValidate := field.Tag.Lookup("validate")
+		if _, hasSchema := field.Tag.Lookup("schema"); !hasValidate && !hasSchema {
+			continue
+		}
+
+		//nolint:exhaustruct
+		schema := &JSONSchema{}
+		err := applyValidateTag(schema, field.StructField)
+		constraints[i] = fieldConstraint{schema: schema, err: err}
+	}
+
+	constraintCache.Store(typ, constraints)
+
+	return constraints
+}
+
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func formatBound(bound float64) string {
+	return strconv.FormatFloat(bound, 'g', -1, 64)
+}
+
+// ValidateValue checks a value against the constraints declared in the validate tags of its struct fields.
+func ValidateValue(value reflect.Value) []ValidationError {
+	return validateValue(value, "", nil)
+}
+
+func validateValue(value reflect.Value, path string, errs []ValidationError) []ValidationError { //nolint:cyclop
+	//nolint:exhaustive
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !value.IsNil() {
+			errs = validateValue(value.Elem(), path, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+
+		for idx := range value.Len() {
+			errs = validateValue(value.Index(idx), path+"/"+strconv.Itoa(idx), errs)
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			errs = validateValue(iter.Value(), path+"/"+escapePointer(fmt.Sprint(iter.Key().Interface())), errs)
+		}
+	case reflect.Struct:
+		if value.Type() == reflect.TypeOf(time.Time{}) {
+			break
+		}
+
+		if isOptional(value.Type()) {
+			if value.FieldByName("Set").Bool() && !value.FieldByName("Null").Bool() {
+				errs = validateValue(value.FieldByName("Value"), path, errs)
+			}
+
+			break
+		}
+
+		constraints := structConstraints(value.Type())
+
+		for idx, field := range StructFields(value.Type()) {
+			fieldValue, err := value.FieldByIndexErr(field.Index)
+			if err != nil {
+				continue
+			}
+
+			fieldPath := path + "/" + escapePointer(field.JSONName)
+
+			if constraint := constraints[idx]; constraint.err != nil {
+				errs = append(errs, ValidationError{Path: fieldPath, Message: constraint.err.Error()})
+			} else if constraint.schema != nil {
+				errs = checkConstraints(constraint.schema, fieldValue, fieldPath, errs)
+			}
+
+			errs = validateValue(fieldValue, fieldPath, errs)
+		}
+	}
+
+	return errs
+}
+
+func checkConstraints( //nolint:cyclop,funlen,gocognit
+	schema *JSONSchema,
+	value reflect.Value,
+	path string,
+	errs []ValidationError,
+) []ValidationError {
+	if isOptional(value.Type()) {
+		if !value.FieldByName("Set").Bool() || value.FieldByName("Null").Bool() {
+			return errs
+		}
+
+		value = value.FieldByName("Value")
+	}
+
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return errs
+		}
+
+		value = value.Elem()
+	}
+
+	fail := func(format string, args ...any) {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	checkNumber := func(number float64) {
+		if schema.Minimum != nil && number < *schema.Minimum {
+			fail("must be at least %s", formatBound(*schema.Minimum))
+		}
+
+		if schema.Maximum != nil && number > *schema.Maximum {
+			fail("must be at most %s", formatBound(*schema.Maximum))
+		}
+
+		if schema.ExclusiveMinimum != nil && number <= *schema.ExclusiveMinimum {
+			fail("must be greater than %s", formatBound(*schema.ExclusiveMinimum))
+		}
+
+		if schema.ExclusiveMaximum != nil && number >= *schema.ExclusiveMaximum {
+			fail("must be less than %s", formatBound(*schema.ExclusiveMaximum))
+		}
+
+		//nolint:mnd
+		if schema.MultipleOf != nil && math.Abs(math.Remainder(number, *schema.MultipleOf)) > 1e-9 {
+			fail("must be a multiple of %s", formatBound(*schema.MultipleOf))
+		}
+	}
+
+	checkLength := func(length int) {
+		if schema.MinLength > 0 && length < schema.MinLength {
+			fail("length must be at least %d", schema.MinLength)
+		}
+
+		if maxLength, found := schema.maxLength(); found && length > maxLength {
+			fail("length must be at most %d", maxLength)
+		}
+	}
+
+	//nolint:exhaustive
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		checkNumber(float64(value.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		checkNumber(float64(value.Uint()))
+	case reflect.Float32, reflect.Float64:
+		checkNumber(value.Float())
+	case reflect.String:
+		str := value.String()
+		checkLength(utf8.RuneCountInString(str))
+
+		if schema.Pattern != "" {
+			if re, err := compilePattern(schema.Pattern); err != nil || !re.MatchString(str) {
+				fail("must match pattern %s", schema.Pattern)
+			}
+		}
+
+		if schema.Format != "" && !checkFormat(schema.Format, str) {
+			fail("must be a valid %s", schema.Format)
+		}
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			checkLength(value.Len())
+
+			break
+		}
+
+		if schema.MinItems > 0 && value.Len() < schema.MinItems {
+			fail("must have at least %d items", schema.MinItems)
+		}
+
+		if maxItems, found := schema.maxItems(); found && value.Len() > maxItems {
+			fail("must have at most %d items", maxItems)
+		}
+
+		if schema.UniqueItems && !uniqueItems(value) {
+			fail("must have unique items")
+		}
+	}
+
+	if schema.Const != nil && !equalJSON(value, schema.Const) {
+		fail("must be %v", schema.Const)
+	}
+
+	return errs
+}
+
+func uniqueItems(value reflect.Value) bool {
+	seen := map[string]struct{}{}
+
+	for idx := range value.Len() {
+		encoded, err := json.Marshal(value.Index(idx).Interface())
+		if err != nil {
+			return true
+		}
+
+		if _, found := seen[string(encoded)]; found {
+			return false
+		}
+
+		seen[string(encoded)] = struct{}{}
+	}
+
+	return true
+}
+
+// equalJSON reports whether value is encoded to the same json as expected, which was parsed from a tag.
+func equalJSON(value reflect.Value, expected any) bool {
+	encoded, err := json.Marshal(value.Interface())
+	if err != nil {
+		return false
+	}
+
+	var decoded any
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(decoded, expected)
+}
@@ -0,0 +1,148 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/DimmyJing/valise/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testValidateItem struct {
+	Price float64 `json:"price" validate:"min=0"`
+}
+
+type testValidate struct {
+	Name  string             `json:"name"  validate:"min=1,max=5,pattern=^[a-z]{1,5}$"`
+	Email string             `json:"email" validate:"format=email"`
+	Age   *int               `json:"age"   validate:"min=18,max=100"`
+	Tags  []string           `json:"tags"  validate:"max=2"`
+	Items []testValidateItem `json:"items"`
+}
+
+func TestValidateValue(t *testing.T) {
+	t.Parallel()
+
+	age := 20
+	valid := testValidate{
+		Name:  "abc",
+		Email: "abc@example.com",
+		Age:   &age,
+		Tags:  []string{"a"},
+		Items: []testValidateItem{{Price: 1}},
+	}
+
+	assert.Empty(t, jsonschema.ValidateValue(reflect.ValueOf(valid)))
+
+	age = 10
+	invalid := testValidate{
+		Name:  "abcdef1",
+		Email: "not an email",
+		Age:   &age,
+		Tags:  []string{"a", "b", "c"},
+		Items: []testValidateItem{{Price: 1}, {Price: -1}},
+	}
+
+	errs := jsonschema.ValidateValue(reflect.ValueOf(invalid))
+	assert.Equal(t, []jsonschema.ValidationError{
+		{Path: "/name", Message: "length must be at most 5"},
+		{Path: "/name", Message: "must match pattern ^[a-z]{1,5}$"},
+		{Path: "/email", Message: "must be a valid email"},
+		{Path: "/age", Message: "must be at least 18"},
+		{Path: "/tags", Message: "must have at most 2 items"},
+		{Path: "/items/1/price", Message: "must be at least 0"},
+	}, errs)
+
+	invalid.Age = nil
+	errs = jsonschema.ValidateValue(reflect.ValueOf(&invalid))
+	assert.Len(t, errs, 5)
+}
+
+type testValidateZero struct {
+	Note  string `json:"note"  validate:"max=0"`
+	Codes []int  `json:"codes" validate:"len=0"`
+}
+
+func TestValidateZeroBounds(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, jsonschema.ValidateValue(reflect.ValueOf(testValidateZero{Note: "", Codes: nil})))
+	assert.Equal(t, []jsonschema.ValidationError{
+		{Path: "/note", Message: "length must be at most 0"},
+		{Path: "/codes", Message: "must have at most 0 items"},
+	}, jsonschema.ValidateValue(reflect.ValueOf(testValidateZero{Note: "a", Codes: []int{1}})))
+
+	schema, err := jsonschema.AnyToSchema(reflect.TypeOf(testValidateZero{})) //nolint:exhaustruct
+	require.NoError(t, err)
+
+	propertiesJSON, err := json.Marshal(schema.Properties)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"note": {"title": "note", "type": "string", "maxLength": 0},
+		"codes": {
+			"title": "codes", "type": "array", "maxItems": 0,
+			"items": {"title": "int", "type": "integer", "format": "int32"}
+		}
+	}`, string(propertiesJSON))
+
+	var decoded jsonschema.JSONSchema
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "string", "maxLength": 0}`), &decoded))
+	assert.Equal(t, 0, decoded.MaxLength)
+
+	decodedJSON, err := json.Marshal(&decoded)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "string", "maxLength": 0}`, string(decodedJSON))
+
+	var set jsonschema.JSONSchema
+	set.SetMaxLength(0)
+	set.SetMaxItems(0)
+
+	setJSON, err := json.Marshal(&set)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"maxLength": 0, "maxItems": 0}`, string(setJSON))
+}
+
+func TestValidateTagSchema(t *testing.T) {
+	t.Parallel()
+
+	//nolint:exhaustruct
+	schema, err := jsonschema.AnyToSchema(reflect.TypeOf(testValidate{}))
+	require.NoError(t, err)
+
+	name, _ := schema.Properties.Get("name")
+	assert.Equal(t, 1, name.MinLength)
+	assert.Equal(t, 5, name.MaxLength)
+	assert.Equal(t, "^[a-z]{1,5}$", name.Pattern)
+
+	email, _ := schema.Properties.Get("email")
+	assert.Equal(t, "email", email.Format)
+
+	age, _ := schema.Properties.Get("age")
+	assert.InEpsilon(t, 18.0, *age.Minimum, 0.0001)
+	assert.InEpsilon(t, 100.0, *age.Maximum, 0.0001)
+
+	tags, _ := schema.Properties.Get("tags")
+	assert.Equal(t, 2, tags.MaxItems)
+
+	_, err = jsonschema.AnyToSchema(reflect.TypeOf(struct {
+		Name string `validate:"min=a"`
+	}{Name: ""}))
+	require.Error(t, err)
+
+	_, err = jsonschema.AnyToSchema(reflect.TypeOf(struct {
+		Name int `validate:"pattern=a"`
+	}{Name: 0}))
+	require.Error(t, err)
+
+	_, err = jsonschema.AnyToSchema(reflect.TypeOf(struct {
+		Name string `validate:"format=unknown"`
+	}{Name: ""}))
+	require.Error(t, err)
+
+	_, err = jsonschema.AnyToSchema(reflect.TypeOf(struct {
+		Name string `validate:"unknown=1"`
+	}{Name: ""}))
+	require.Error(t, err)
+}
@@ -0,0 +1,425 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DimmyJing/valise/jsonschema"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Client calls valise rpc routes, placing input fields according to their in tags the same way the handlers
+// bind them. It is used by the clients generated with GoClientGen.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	editors    []func(context.Context, *http.Request) error
+	propagator propagation.TextMapPropagator
+}
+
+type ClientOption interface {
+	privateClientOption()
+}
+
+type withHTTPClient struct {
+	client *http.Client
+}
+
+func (w withHTTPClient) privateClientOption() {}
+
+func WithHTTPClient(client *http.Client) withHTTPClient {
+	return withHTTPClient{client: client}
+}
+
+type withRequestEditor struct {
+	editor func(context.Context, *http.Request) error
+}
+
+func (w withRequestEditor) privateClientOption() {}
+
+// WithRequestEditor edits every request before it is sent, for example to add authentication headers.
+func WithRequestEditor(editor func(context.Context, *http.Request) error) withRequestEditor {
+	return withRequestEditor{editor: editor}
+}
+
+type withPropagator struct {
+	propagator propagation.TextMapPropagator
+}
+
+func (w withPropagator) privateClientOption() {}
+
+// WithPropagator sets the propagator that injects the trace context into requests, which is the global propagator of
+// otel by default.
+func WithPropagator(propagator propagation.TextMapPropagator) withPropagator {
+	return withPropagator{propagator: propagator}
+}
+
+func NewClient(baseURL string, options ...ClientOption) *Client {
+	client := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		editors:    nil,
+		propagator: nil,
+	}
+
+	for _, option := range options {
+		switch opt := option.(type) {
+		case withHTTPClient:
+			client.httpClient = opt.client
+		case withRequestEditor:
+			client.editors = append(client.editors, opt.editor)
+		case withPropagator:
+			client.propagator = opt.propagator
+		}
+	}
+
+	return client
+}
+
+// ClientError is returned by Client.Do for error responses. It unwraps to the error registered for its code.
+type ClientError struct {
+	StatusCode int
+	ErrorMessage
+}
+
+func (e *ClientError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+
+	if e.Code != "" {
+		return fmt.Sprintf("%d %s: %s", e.StatusCode, e.Code, msg)
+	}
+
+	return fmt.Sprintf("%d: %s", e.StatusCode, msg)
+}
+
+func (e *ClientError) Unwrap() error {
+	errorRegistryMu.RLock()
+	defer errorRegistryMu.RUnlock()
+
+	if e.Code == "" {
+		return nil
+	}
+
+	for _, registered := range errorRegistry {
+		if registered.code == e.Code {
+			return registered.err
+		}
+	}
+
+	return nil
+}
+
+var errInvalidClientValue = errors.New("invalid client value")
+
+// Do sends input to the route at method and path, and decodes the response into output, which must be a pointer.
+func (c *Client) Do( //nolint:funlen,cyclop
+	ctx context.Context,
+	method string,
+	path string,
+	requestContentType string,
+	input any,
+	output any,
+) error {
+	inputValue := reflect.ValueOf(input)
+	hasBody := slices.Contains(hasBodyMethods, method)
+
+	inputFieldAttrsMap, err := getInputFieldAttrs(inputValue.Type(), hasBody)
+	if err != nil {
+		return fmt.Errorf("failed to get input field attrs: %w", err)
+	}
+
+	inputAny, err := jsonschema.ValueToAny(inputValue)
+	if err != nil {
+		return fmt.Errorf("error converting input: %w", err)
+	}
+
+	inputMap, ok := inputAny.(map[string]any)
+	if !ok {
+		return fmt.Errorf("input %T is not a struct: %w", input, errInvalidClientValue)
+	}
+
+	query := url.Values{}
+	header := http.Header{}
+	cookies := []*http.Cookie{}
+	body := map[string]any{}
+
+	for key, value := range inputMap {
+		attrs, found := inputFieldAttrsMap[key]
+
+		switch {
+		case !found:
+			body[key] = value
+		case attrs.inPath:
+			path = strings.ReplaceAll(path, "{"+key+"}", url.PathEscape(fmt.Sprint(value)))
+		case attrs.inQuery:
+			for _, elem := range clientValues(value) {
+				query.Add(key, elem)
+			}
+		case attrs.inHeader:
+			for _, elem := range clientValues(value) {
+				header.Add(key, elem)
+			}
+		case attrs.inCookie:
+			if elems := clientValues(value); len(elems) > 0 {
+				//nolint:exhaustruct
+				cookies = append(cookies, &http.Cookie{Name: key, Value: elems[0]})
+			}
+		default:
+			body[key] = value
+		}
+	}
+
+	// path values are escaped, so a brace left in path is a parameter that was not set, e.g. dropped by omitempty.
+	if _, param, found := strings.Cut(path, "{"); found {
+		param, _, _ = strings.Cut(param, "}")
+
+		return fmt.Errorf("path parameter %s is not set: %w", param, errInvalidClientValue)
+	}
+
+	requestURL := c.baseURL + path
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	var bodyReader io.Reader
+
+	if hasBody {
+		var contentType string
+
+		bodyReader, contentType, err = encodeClientBody(body, requestContentType)
+		if err != nil {
+			return err
+		}
+
+		header.Set(echo.HeaderContentType, contentType)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, bodyReader)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header = header
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	propagator := c.propagator
+	if propagator == nil {
+		propagator = otel.GetTextMapPropagator()
+	}
+
+	propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	for _, editor := range c.editors {
+		if err := editor(ctx, req); err != nil {
+			return fmt.Errorf("error editing request: %w", err)
+		}
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %w", err)
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		//nolint:exhaustruct
+		clientErr := &ClientError{StatusCode: res.StatusCode}
+		_ = json.Unmarshal(resBody, &clientErr.ErrorMessage)
+
+		return clientErr
+	}
+
+	return decodeClientResponse(res, resBody, reflect.ValueOf(output))
+}
+
+func clientValues(value any) []string {
+	switch val := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		result := make([]string, 0, len(val))
+		for _, elem := range val {
+			result = append(result, clientValues(elem)...)
+		}
+
+		return result
+	case time.Time:
+		return []string{val.Format(time.RFC3339Nano)}
+	default:
+		return []string{fmt.Sprint(val)}
+	}
+}
+
+func encodeClientBody(body map[string]any, requestContentType string) (io.Reader, string, error) {
+	switch requestContentType {
+	case echo.MIMEApplicationForm:
+		form := url.Values{}
+
+		for key, value := range body {
+			for _, elem := range clientValues(value) {
+				form.Add(key, elem)
+			}
+		}
+
+		return strings.NewReader(form.Encode()), requestContentType, nil
+	case echo.MIMEMultipartForm:
+		var buf bytes.Buffer
+
+		writer := multipart.NewWriter(&buf)
+
+		for key, value := range body {
+			if fileBytes, ok := value.([]byte); ok {
+				part, err := writer.CreateFormFile(key, key)
+				if err != nil {
+					return nil, "", fmt.Errorf("error creating form file %s: %w", key, err)
+				}
+
+				if _, err := part.Write(fileBytes); err != nil {
+					return nil, "", fmt.Errorf("error writing form file %s: %w", key, err)
+				}
+
+				continue
+			}
+
+			for _, elem := range clientValues(value) {
+				if err := writer.WriteField(key, elem); err != nil {
+					return nil, "", fmt.Errorf("error writing form field %s: %w", key, err)
+				}
+			}
+		}
+
+		if err := writer.Close(); err != nil {
+			return nil, "", fmt.Errorf("error closing multipart writer: %w", err)
+		}
+
+		return &buf, writer.FormDataContentType(), nil
+	default:
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return nil, "", fmt.Errorf("error encoding request body: %w", err)
+		}
+
+		return bytes.NewReader(bodyBytes), echo.MIMEApplicationJSON, nil
+	}
+}
+
+func decodeClientResponse(res *http.Response, resBody []byte, output reflect.Value) error { //nolint:cyclop
+	if output.Kind() != reflect.Pointer || output.IsNil() {
+		return fmt.Errorf("output must be a non-nil pointer: %w", errInvalidClientValue)
+	}
+
+	outputValue := output.Elem()
+
+	if outputValue.Kind() == reflect.Slice && outputValue.Type().Elem().Kind() == reflect.Uint8 {
+		outputValue.SetBytes(resBody)
+
+		return nil
+	}
+
+	outputAttrs, err := getOutputFieldAttrs(outputValue.Type())
+	if err != nil {
+		return fmt.Errorf("failed to get output field attrs: %w", err)
+	}
+
+	if outputAttrs.statusField != nil {
+		statusField, err := jsonschema.FieldByIndex(outputValue, outputAttrs.statusField)
+		if err != nil {
+			return fmt.Errorf("failed to set status: %w", err)
+		}
+
+		statusField.SetInt(int64(res.StatusCode))
+	}
+
+	// out fields are not part of the json, and are usually tagged json:"-", so they are set on their fields instead.
+	for name, index := range outputAttrs.headers {
+		if err := setClientHeader(outputValue, index, res.Header.Values(name)); err != nil {
+			return fmt.Errorf("failed to set header %s: %w", name, err)
+		}
+	}
+
+	if isNoContentStatus(res.StatusCode) {
+		return nil
+	}
+
+	var resMap map[string]any
+
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get(echo.HeaderContentType))
+	if mediaType == echo.MIMEApplicationJSON {
+		if err := json.Unmarshal(resBody, &resMap); err != nil {
+			return fmt.Errorf("error decoding response json: %w", err)
+		}
+	}
+
+	if resMap == nil {
+		resMap = map[string]any{}
+	}
+
+	outNames := []string{}
+	if outputAttrs.statusField != nil {
+		outNames = append(outNames, outputAttrs.statusName)
+	}
+
+	for name := range outputAttrs.headers {
+		outNames = append(outNames, name)
+	}
+
+	err = jsonschema.AnyToValue(resMap, outputValue, jsonschema.WithAllowUnknownFields(),
+		jsonschema.WithSkippedFields(outNames...))
+	if err != nil {
+		return fmt.Errorf("error converting response: %w", err)
+	}
+
+	return nil
+}
+
+func setClientHeader(outputValue reflect.Value, index []int, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	headerField, err := jsonschema.FieldByIndex(outputValue, index)
+	if err != nil {
+		return fmt.Errorf("failed to get header field: %w", err)
+	}
+
+	switch {
+	case headerField.Kind() == reflect.Slice:
+		err = jsonschema.AnyToValue(values, headerField)
+	case headerField.Type() == reflect.TypeOf(time.Time{}):
+		if headerTime, err := http.ParseTime(values[0]); err == nil {
+			headerField.Set(reflect.ValueOf(headerTime))
+		}
+	default:
+		err = jsonschema.AnyToValue(values[0], headerField)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error converting header: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package rpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/DimmyJing/valise/rpc"
+	"github.com/DimmyJing/valise/rpc/internal/clienttest"
+	"github.com/DimmyJing/valise/vctx"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type testOmittedPathInput struct {
+	ID string `in:"path" json:"id,omitempty"`
+}
+
+type testMovedOutput struct {
+	Status   int    `json:"-"    out:"status"`
+	Location string `json:"-"    out:"header"`
+	Name     string `json:"name"`
+}
+
+func HandlerMoved(inp testInput1, ctx vctx.Context) (testMovedOutput, error) {
+	if inp.Name == "gone" {
+		return testMovedOutput{Status: http.StatusNoContent, Location: "/x/gone", Name: ""}, nil
+	}
+
+	return testMovedOutput{Status: 0, Location: "/x/" + inp.Name, Name: inp.Name}, nil
+}
+
+func TestClient(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	rpc.RegisterError(errTestItemNotFound, http.StatusNotFound, "item_not_found", "item not found")
+
+	ech := echo.New()
+	ech.HTTPErrorHandler = rpc.HTTPErrorHandler
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	_, err := oapi.PUT(ech, "/items/:id", HandlerHeader, rpc.WithRequestContentType(echo.MIMEApplicationJSON))
+	require.NoError(t, err)
+
+	_, err = oapi.POST(ech, "/created", HandlerCreated, rpc.WithStatus(http.StatusCreated))
+	require.NoError(t, err)
+
+	_, err = oapi.GET(ech, "/moved", HandlerMoved)
+	require.NoError(t, err)
+
+	_, err = oapi.GET(ech, "/item", HandlerRegisteredError)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(ech)
+	defer server.Close()
+
+	traceID := trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	//nolint:exhaustruct
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	var traceParent string
+
+	client := rpc.NewClient(server.URL, rpc.WithPropagator(propagation.TraceContext{}),
+		rpc.WithRequestEditor(func(ctx context.Context, req *http.Request) error {
+			traceParent = req.Header.Get("traceparent")
+
+			return nil
+		}))
+
+	var headerOutput testHeaderOutput
+
+	err = client.Do(ctx, http.MethodPut, "/items/{id}", echo.MIMEApplicationJSON, testHeaderInput{
+		ID:             "abc",
+		IdempotencyKey: "key",
+		IfMatch:        []string{"a", "b"},
+		Session:        "sess",
+	}, &headerOutput)
+	require.NoError(t, err)
+	assert.Equal(t, testHeaderOutput{ID: "abc", IdempotencyKey: "key", IfMatch: []string{"a", "b"}, Session: "sess"},
+		headerOutput)
+	assert.Contains(t, traceParent, traceID.String())
+
+	var createdOutput testCreatedOutput
+
+	err = client.Do(ctx, http.MethodPost, "/created", echo.MIMEApplicationForm, testInput1{Name: "a"}, &createdOutput)
+	require.NoError(t, err)
+	assert.Equal(t, testCreatedOutput{ID: "a", Location: "/items/a"}, createdOutput)
+
+	// out fields tagged json:"-" are set from the response rather than the json.
+	var movedOutput testMovedOutput
+
+	err = client.Do(ctx, http.MethodGet, "/moved", "", testInput1{Name: "n"}, &movedOutput)
+	require.NoError(t, err)
+	assert.Equal(t, testMovedOutput{Status: http.StatusOK, Location: "/x/n", Name: "n"}, movedOutput)
+
+	movedOutput = testMovedOutput{Status: 0, Location: "", Name: ""}
+
+	err = client.Do(ctx, http.MethodGet, "/moved", "", testInput1{Name: "gone"}, &movedOutput)
+	require.NoError(t, err)
+	assert.Equal(t, testMovedOutput{Status: http.StatusNoContent, Location: "/x/gone", Name: ""}, movedOutput)
+
+	var output testOutput1
+
+	err = client.Do(ctx, http.MethodGet, "/item", "", testInput1{Name: "a"}, &output)
+
+	var clientErr *rpc.ClientError
+
+	require.ErrorAs(t, err, &clientErr)
+	assert.Equal(t, http.StatusNotFound, clientErr.StatusCode)
+	assert.Equal(t, "item_not_found", clientErr.Code)
+	assert.True(t, errors.Is(err, errTestItemNotFound))
+
+	err = client.Do(ctx, http.MethodPut, "/items/{id}", echo.MIMEApplicationJSON, testOmittedPathInput{ID: ""},
+		&headerOutput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "path parameter id is not set")
+}
+
+// buildGenerated builds the go package generated in dir as if it were in the internal directory of the module, so
+// that it can import the handler types of the tests.
+func buildGenerated(t *testing.T, dir string) {
+	t.Helper()
+
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	pkgDir := filepath.Join(wd, "internal", "clienttest", filepath.Base(dir))
+	replace := map[string]string{}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	require.NoError(t, err)
+
+	for _, file := range files {
+		replace[filepath.Join(pkgDir, filepath.Base(file))] = file
+	}
+
+	overlay, err := json.Marshal(map[string]any{"Replace": replace})
+	require.NoError(t, err)
+
+	overlayPath := filepath.Join(t.TempDir(), "overlay.json")
+	require.NoError(t, os.WriteFile(overlayPath, overlay, 0o600))
+
+	output, err := exec.Command(goBin, "build", "-overlay", overlayPath, pkgDir).CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+func TestGoClientGen(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	_, err := oapi.GET(ech, "/widgets/:id", clienttest.HandlerWidget)
+	require.NoError(t, err)
+
+	// the types of the handler are unexported, so the route is skipped rather than failing the generation.
+	_, err = oapi.GET(ech, "/test", HandlerTest1)
+	require.NoError(t, err)
+
+	require.NoError(t, oapi.Flush(ech))
+
+	dir := filepath.Join(t.TempDir(), "widgetclient")
+	require.NoError(t, oapi.GoClientGen(dir, "widgetclient"))
+
+	source := readGenerated(t, dir, "client.go")
+	assert.Contains(t, source, "package widgetclient")
+	assert.Contains(t, source, `clienttest "github.com/DimmyJing/valise/rpc/internal/clienttest"`)
+	assert.Contains(t, source, "func (c *Client) GetWidgetsById(ctx context.Context, input clienttest.WidgetInput) "+
+		"(clienttest.WidgetOutput, error) {")
+	assert.Contains(t, source, `err := c.Do(ctx, "GET", "/widgets/{id}", "", input, &output)`)
+	assert.NotContains(t, source, "GetTest")
+	assert.NotContains(t, source, "rpc_test")
+
+	buildGenerated(t, dir)
+}
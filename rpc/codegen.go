@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
 
@@ -14,24 +15,22 @@ import (
 	orderedmap "github.com/wk8/go-ordered-map/v2"
 )
 
-func createStub( //nolint:funlen,cyclop
+func createStub( //nolint:funlen,cyclop,gocognit,gocyclo
+	pathName string,
 	path string,
 	method string,
 	description string,
 	requestBody *jsonschema.JSONSchema,
 	req *jsonschema.JSONSchema,
+	pathParams *jsonschema.JSONSchema,
+	headers *jsonschema.JSONSchema,
+	cookies *jsonschema.JSONSchema,
 	res *jsonschema.JSONSchema,
+	messages *openAPIWebSocket,
+	errorResponses []errorResponse,
 	reqContentType string,
 	resContentType string,
 ) (string, error) {
-	pathSplit := strings.Split(path, "/")
-
-	origPathName := "{"
-	for splitIdx := len(pathSplit) - 1; strings.Contains(origPathName, "{"); splitIdx-- {
-		origPathName = pathSplit[splitIdx]
-	}
-
-	pathName := strings.ToUpper(string(origPathName[0])) + origPathName[1:]
 	result := ""
 
 	if requestBody != nil {
@@ -52,12 +51,68 @@ func createStub( //nolint:funlen,cyclop
 		result += inputType + "\n\n"
 	}
 
-	outputType, err := jsonschema.JSONSchemaToTS(res, "export type "+pathName+"Response = ")
-	if err != nil {
-		return "", fmt.Errorf("failed to convert json schema to ts: %w", err)
+	if pathParams != nil {
+		paramsType, err := jsonschema.JSONSchemaToTS(pathParams, "export type "+pathName+"Params = ")
+		if err != nil {
+			return "", fmt.Errorf("failed to convert json schema to ts: %w", err)
+		}
+
+		result += paramsType + "\n\n"
+	}
+
+	if headers != nil {
+		headersType, err := jsonschema.JSONSchemaToTS(headers, "export type "+pathName+"RequestHeaders = ")
+		if err != nil {
+			return "", fmt.Errorf("failed to convert json schema to ts: %w", err)
+		}
+
+		result += headersType + "\n\n"
+	}
+
+	if cookies != nil {
+		cookiesType, err := jsonschema.JSONSchemaToTS(cookies, "export type "+pathName+"RequestCookies = ")
+		if err != nil {
+			return "", fmt.Errorf("failed to convert json schema to ts: %w", err)
+		}
+
+		result += cookiesType + "\n\n"
+	}
+
+	if messages != nil {
+		clientType, err := jsonschema.JSONSchemaToTS(messages.ClientMessage, "export type "+pathName+"ClientMessage = ")
+		if err != nil {
+			return "", fmt.Errorf("failed to convert json schema to ts: %w", err)
+		}
+
+		serverType, err := jsonschema.JSONSchemaToTS(messages.ServerMessage, "export type "+pathName+"ServerMessage = ")
+		if err != nil {
+			return "", fmt.Errorf("failed to convert json schema to ts: %w", err)
+		}
+
+		result += clientType + "\n\n" + serverType + "\n\n"
+	} else {
+		outputType, err := jsonschema.JSONSchemaToTS(res, "export type "+pathName+"Response = ")
+		if err != nil {
+			return "", fmt.Errorf("failed to convert json schema to ts: %w", err)
+		}
+
+		result += outputType + "\n\n"
 	}
 
-	result += outputType + "\n\n"
+	if len(errorResponses) > 0 {
+		result += "export type " + pathName + "Error ="
+
+		for _, errorResponse := range errorResponses {
+			bodyType, err := jsonschema.JSONSchemaToTS(errorResponse.body, "")
+			if err != nil {
+				return "", fmt.Errorf("failed to convert json schema to ts: %w", err)
+			}
+
+			result += "\n  | { status: " + errorResponse.status + ", body: " + bodyType + " }"
+		}
+
+		result += "\n\n"
+	}
 
 	result += jsonschema.FormatComment(description) + "export type " + pathName + " = {"
 
@@ -65,14 +120,43 @@ func createStub( //nolint:funlen,cyclop
 		result += "\n  body: " + pathName + "RequestBody,"
 	}
 
-	if req != nil {
+	// routes without query parameters can leave the query out.
+	if req != nil && req.Properties.Len() == 0 {
+		result += "\n  query?: " + pathName + "Request,"
+	} else if req != nil {
 		result += "\n  query: " + pathName + "Request,"
 	}
 
-	result += "\n  response: " + pathName + "Response,"
+	if pathParams != nil {
+		result += "\n  params: " + pathName + "Params,"
+	}
+
+	if headers != nil {
+		result += "\n  headers: " + pathName + "RequestHeaders,"
+	}
+
+	if cookies != nil {
+		result += "\n  cookies: " + pathName + "RequestCookies,"
+	}
+
+	if messages != nil {
+		result += "\n  send: " + pathName + "ClientMessage,"
+		result += "\n  receive: " + pathName + "ServerMessage,"
+	} else {
+		result += "\n  response: " + pathName + "Response,"
+	}
+
+	if len(errorResponses) > 0 {
+		result += "\n  errors: " + pathName + "Error,"
+	}
+
 	result += "\n  method: \"" + method + "\","
 	result += "\n  path: \"" + path + "\","
 
+	if messages != nil {
+		result += "\n  websocket: true,"
+	}
+
 	if reqContentType != "" {
 		result += "\n  requestContentType: \"" + reqContentType + "\","
 	}
@@ -86,49 +170,112 @@ func createStub( //nolint:funlen,cyclop
 	return result, nil
 }
 
-var errUnsupportedMethod = errors.New("unsupported method")
+// stubName returns the name of the stub type of a path, taken from its last non-parameter segment.
+func stubName(path string) string {
+	pathSplit := strings.Split(path, "/")
 
-func processPath(operation openAPIOperation, method string, pathString string) (string, error) { //nolint:funlen,cyclop
-	operationDescription := operation.Description
+	origPathName := "{"
+	for splitIdx := len(pathSplit) - 1; strings.Contains(origPathName, "{"); splitIdx-- {
+		origPathName = pathSplit[splitIdx]
+	}
 
-	var (
-		requestBodySchema   *jsonschema.JSONSchema
-		requestSchema       *jsonschema.JSONSchema
-		requestContentType  string
-		responseSchema      *jsonschema.JSONSchema
-		responseContentType string
-	)
+	return strings.ToUpper(string(origPathName[0])) + origPathName[1:]
+}
 
-	if slices.Contains(hasBodyMethods, strings.ToUpper(method)) {
-		body := operation.RequestBody
+type stubRoute struct {
+	method string
+	path   string
+}
 
-		if len(body.Content) != 1 {
-			return "", fmt.Errorf("unsupported number of content types %d: %w", len(body.Content), errUnsupportedMethod)
+// stubNames names the stubs of routes with stubName. Routes that would share a name within their file are told apart
+// by their method, e.g. GetItems and PostItems, and then by their whole path, e.g. GetItemsById.
+func stubNames(routes []stubRoute) []string {
+	namers := []func(route stubRoute) string{
+		func(route stubRoute) string {
+			return stubName(route.path)
+		},
+		func(route stubRoute) string {
+			return strings.ToUpper(route.method[:1]) + strings.ToLower(route.method[1:]) + stubName(route.path)
+		},
+		func(route stubRoute) string {
+			name := clientFunctionName(route.method, route.path)
+
+			return strings.ToUpper(name[:1]) + name[1:]
+		},
+	}
+
+	names := make([]string, len(routes))
+
+	for idx, route := range routes {
+		for _, namer := range namers {
+			names[idx] = namer(route)
+
+			shared := false
+
+			for otherIdx, other := range routes {
+				if otherIdx != idx && stubFileName(other.path) == stubFileName(route.path) && namer(other) == names[idx] {
+					shared = true
+
+					break
+				}
+			}
+
+			if !shared {
+				break
+			}
 		}
+	}
 
-		for key, val := range body.Content {
-			schema := val.Schema
-			requestContentType = key
-			requestBodySchema = &schema
+	return names
+}
+
+// stubFileName returns the name of the file of the stubs of a path, its first segment.
+func stubFileName(path string) string {
+	return strings.Split(path, "/")[1]
+}
+
+var errUnsupportedMethod = errors.New("unsupported method")
+
+type errorResponse struct {
+	status string
+	body   *jsonschema.JSONSchema
+}
+
+func getErrorResponses(responses map[string]openAPIResponse) []errorResponse {
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		if strings.HasPrefix(code, "4") || strings.HasPrefix(code, "5") {
+			codes = append(codes, code)
 		}
+	}
 
-		if body.Description != "" {
-			requestBodySchema.Description = body.Description
+	slices.Sort(codes)
+
+	result := make([]errorResponse, 0, len(codes))
+
+	for _, code := range codes {
+		for _, val := range responses[code].Content {
+			schema := val.Schema
+			result = append(result, errorResponse{status: code, body: &schema})
+
+			break
 		}
 	}
 
-	params := operation.Parameters
-	requestSet := false
+	return result
+}
+
+func parametersToObject(params []jsonschema.OpenAPIParameter, paramIn string) *jsonschema.JSONSchema {
+	var result *jsonschema.JSONSchema
 
 	for _, param := range params {
-		if param.In != "query" {
+		if param.In != paramIn {
 			continue
 		}
 
-		if !requestSet {
-			requestSet = true
+		if result == nil {
 			//nolint:exhaustruct
-			requestSchema = &jsonschema.JSONSchema{
+			result = &jsonschema.JSONSchema{
 				Type:                 "object",
 				Properties:           orderedmap.New[string, *jsonschema.JSONSchema](),
 				AdditionalProperties: &jsonschema.JSONSchemaFalse,
@@ -136,18 +283,60 @@ func processPath(operation openAPIOperation, method string, pathString string) (
 		}
 
 		schema := param.Schema
-		requestSchema.Properties.Set(param.Name, schema)
+		result.Properties.Set(param.Name, schema)
 
 		if param.Description != "" {
 			schema.Description = param.Description
 		}
 
 		if param.Required {
-			requestSchema.Required = append(requestSchema.Required, param.Name)
+			result.Required = append(result.Required, param.Name)
 		}
 	}
 
-	if !requestSet && requestBodySchema == nil {
+	return result
+}
+
+func processPath( //nolint:funlen,cyclop
+	operation openAPIOperation,
+	method string,
+	pathString string,
+	name string,
+) (string, error) {
+	operationDescription := operation.Description
+
+	var (
+		requestBodySchema   *jsonschema.JSONSchema
+		requestSchema       *jsonschema.JSONSchema
+		requestContentType  string
+		responseSchema      *jsonschema.JSONSchema
+		responseContentType string
+	)
+
+	if slices.Contains(hasBodyMethods, strings.ToUpper(method)) {
+		body := operation.RequestBody
+
+		if len(body.Content) != 1 {
+			return "", fmt.Errorf("unsupported number of content types %d: %w", len(body.Content), errUnsupportedMethod)
+		}
+
+		for key, val := range body.Content {
+			schema := val.Schema
+			requestContentType = key
+			requestBodySchema = &schema
+		}
+
+		if body.Description != "" {
+			requestBodySchema.Description = body.Description
+		}
+	}
+
+	requestSchema = parametersToObject(operation.Parameters, "query")
+	pathParamsSchema := parametersToObject(operation.Parameters, "path")
+	headersSchema := parametersToObject(operation.Parameters, "header")
+	cookiesSchema := parametersToObject(operation.Parameters, "cookie")
+
+	if requestSchema == nil && requestBodySchema == nil {
 		//nolint:exhaustruct
 		requestSchema = &jsonschema.JSONSchema{
 			Type:                 "object",
@@ -156,28 +345,39 @@ func processPath(operation openAPIOperation, method string, pathString string) (
 		}
 	}
 
-	if len(operation.Responses["200"].Content) != 1 {
-		return "", fmt.Errorf("unsupported number of content types %d: %w",
-			len(operation.Responses["200"].Content), errUnsupportedMethod)
-	}
+	response := successResponse(operation.Responses)
 
-	for key, val := range operation.Responses["200"].Content {
-		schema := val.Schema
-		responseContentType = key
-		responseSchema = &schema
-	}
+	switch len(response.Content) {
+	case 0:
+		//nolint:exhaustruct
+		responseSchema = &jsonschema.JSONSchema{Type: "null"}
+	case 1:
+		for key, val := range response.Content {
+			schema := val.Schema
+			responseContentType = key
+			responseSchema = &schema
+		}
 
-	if operation.Responses["200"].Description != "" {
-		responseSchema.Description = operation.Responses["200"].Description
+		if response.Description != "" {
+			responseSchema.Description = response.Description
+		}
+	default:
+		return "", fmt.Errorf("unsupported number of content types %d: %w", len(response.Content), errUnsupportedMethod)
 	}
 
 	defs, err := createStub(
+		name,
 		pathString,
 		method,
 		operationDescription,
 		requestBodySchema,
 		requestSchema,
+		pathParamsSchema,
+		headersSchema,
+		cookiesSchema,
 		responseSchema,
+		operation.WebSocket,
+		getErrorResponses(operation.Responses),
 		requestContentType,
 		responseContentType,
 	)
@@ -188,6 +388,31 @@ func processPath(operation openAPIOperation, method string, pathString string) (
 	return defs, nil
 }
 
+// successResponse returns the lowest 2xx response, preferring responses with a body.
+func successResponse(responses map[string]openAPIResponse) openAPIResponse {
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+
+	slices.Sort(codes)
+
+	for _, code := range codes {
+		if len(responses[code].Content) > 0 {
+			return responses[code]
+		}
+	}
+
+	if len(codes) > 0 {
+		return responses[codes[0]]
+	}
+
+	//nolint:exhaustruct
+	return openAPIResponse{}
+}
+
 func (o *OpenAPI) CodeGen(path string) error { //nolint:cyclop,funlen
 	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
 		//nolint:mnd
@@ -209,41 +434,81 @@ func (o *OpenAPI) CodeGen(path string) error { //nolint:cyclop,funlen
 
 	doc := o.document
 	files := make(map[string][]string)
+	operations := []clientOperation{}
+	routes := []stubRoute{}
 
 	for pair := doc.Paths.Oldest(); pair != nil; pair = pair.Next() {
-		key, path := pair.Key, pair.Value
+		methods := make([]string, 0, len(pair.Value))
+		for method := range pair.Value {
+			methods = append(methods, method)
+		}
+
+		slices.Sort(methods)
+
+		for _, method := range methods {
+			routes = append(routes, stubRoute{method: method, path: pair.Key})
+		}
+	}
+
+	names := stubNames(routes)
 
-		for method, pathItem := range path {
-			defs, err := processPath(pathItem, method, key)
+	for routeIdx, route := range routes {
+		key, method := route.path, route.method
+		pathItem, _ := doc.Paths.Get(key)
+		operation := pathItem[method]
+
+		defs, err := processPath(operation, method, key, names[routeIdx])
+		if err != nil {
+			return err
+		}
+
+		fileName := stubFileName(key)
+
+		if operation.WebSocket == nil {
+			operations = append(operations, clientOperation{
+				fileName:            fileName,
+				stubName:            names[routeIdx],
+				method:              strings.ToUpper(method),
+				path:                key,
+				requestContentType:  requestContentType(operation),
+				responseContentType: responseContentType(operation),
+			})
+		}
+
+		files[fileName] = append(files[fileName], defs)
+	}
+
+	commonTypes := []string{"DateString"}
+
+	var commonBuilder strings.Builder
+
+	commonBuilder.WriteString("export type DateString = string;\n")
+
+	if doc.Components != nil && doc.Components.Schemas != nil {
+		for pair := doc.Components.Schemas.Oldest(); pair != nil; pair = pair.Next() {
+			componentType, err := jsonschema.JSONSchemaToTS(pair.Value, "export type "+pair.Key+" = ")
 			if err != nil {
-				return err
+				return fmt.Errorf("failed to convert component %s to ts: %w", pair.Key, err)
 			}
 
-			splitPath := strings.Split(key, "/")
-			fileName := splitPath[1]
+			commonTypes = append(commonTypes, pair.Key)
 
-			if val, found := files[fileName]; found {
-				val = append(val, defs)
-				files[fileName] = val
-			} else {
-				files[fileName] = []string{defs}
-			}
+			commonBuilder.WriteString("\n" + componentType + "\n")
 		}
 	}
 
 	for key, val := range files {
 		var builder strings.Builder
 
-		const dateString = "import type { DateString } from \"./common\"\n\n"
-
 		for _, defs := range val {
 			builder.WriteString(defs)
 			builder.WriteString("\n\n")
 		}
 
 		builderString := builder.String()
-		if strings.Contains(builderString, "DateString") {
-			builderString = dateString + builderString
+
+		if imports := usedTypes(builderString, commonTypes); len(imports) > 0 {
+			builderString = "import type { " + strings.Join(imports, ", ") + " } from \"./common\"\n\n" + builderString
 		}
 
 		fileContent := []byte(strings.TrimSpace(builderString))
@@ -255,10 +520,7 @@ func (o *OpenAPI) CodeGen(path string) error { //nolint:cyclop,funlen
 		}
 	}
 
-	var builder strings.Builder
-
-	builder.WriteString("export type DateString = string;\n")
-	fileContent := []byte(strings.TrimSpace(builder.String()))
+	fileContent := []byte(strings.TrimSpace(commonBuilder.String()))
 
 	//nolint:gosec,mnd
 	err := os.WriteFile(filepath.Join(path, "common.ts"), fileContent, 0o644)
@@ -266,5 +528,48 @@ func (o *OpenAPI) CodeGen(path string) error { //nolint:cyclop,funlen
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
+	errorTypes := make([]string, 0, len(o.errorRefs))
+	for _, ref := range o.errorRefs {
+		errorTypes = append(errorTypes, jsonschema.RefName(ref))
+	}
+
+	//nolint:gosec,mnd
+	err = os.WriteFile(filepath.Join(path, "client.ts"), []byte(generateClient(operations, errorTypes)), 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
 	return nil
 }
+
+func requestContentType(operation openAPIOperation) string {
+	if operation.RequestBody == nil {
+		return ""
+	}
+
+	for contentType := range operation.RequestBody.Content {
+		return contentType
+	}
+
+	return ""
+}
+
+func responseContentType(operation openAPIOperation) string {
+	for contentType := range successResponse(operation.Responses).Content {
+		return contentType
+	}
+
+	return ""
+}
+
+func usedTypes(source string, typeNames []string) []string {
+	result := []string{}
+
+	for _, typeName := range typeNames {
+		if regexp.MustCompile(`\b` + regexp.QuoteMeta(typeName) + `\b`).MatchString(source) {
+			result = append(result, typeName)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,258 @@
+package rpc_test
+
+import (
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DimmyJing/valise/rpc"
+	"github.com/DimmyJing/valise/vctx"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readGenerated(t *testing.T, dir string, name string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	require.NoError(t, err)
+
+	return string(content)
+}
+
+func TestCodeGenErrors(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+	oapi.SetDefaultErrors(http.StatusInternalServerError)
+
+	_, err := oapi.GET(ech, "/test", HandlerTest1, rpc.WithErrors(http.StatusNotFound, http.StatusConflict))
+	require.NoError(t, err)
+
+	require.NoError(t, oapi.Flush(ech))
+
+	dir := t.TempDir()
+	require.NoError(t, oapi.CodeGen(dir))
+
+	doc := readGenerated(t, dir, "swagger.json")
+	assert.Contains(t, doc, `"$ref": "#/components/schemas/ErrorMessage"`)
+	assert.Contains(t, doc, `"404": {`)
+	assert.Contains(t, doc, `"500": {`)
+
+	common := readGenerated(t, dir, "common.ts")
+	assert.Contains(t, common, "export type ErrorMessage = {")
+
+	stub := readGenerated(t, dir, "test.ts")
+	assert.Contains(t, stub, `import type { ErrorMessage } from "./common"`)
+	assert.Contains(t, stub, `export type TestError =
+  | { status: 404, body: ErrorMessage }
+  | { status: 409, body: ErrorMessage }
+  | { status: 500, body: ErrorMessage }`)
+	assert.Contains(t, stub, "errors: TestError,")
+
+	client := readGenerated(t, dir, "client.ts")
+	assert.Contains(t, client, `import type { ErrorMessage } from "./common"`)
+	assert.Contains(t, client, "type ErrorType = ErrorMessage\n")
+}
+
+func TestCodeGenClient(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	_, err := oapi.PUT(ech, "/items/:id", HandlerHeader, rpc.WithRequestContentType(echo.MIMEApplicationJSON))
+	require.NoError(t, err)
+
+	_, err = oapi.GET(ech, "/test", HandlerTest1)
+	require.NoError(t, err)
+
+	require.NoError(t, oapi.Flush(ech))
+
+	dir := t.TempDir()
+	require.NoError(t, oapi.CodeGen(dir))
+
+	stub := readGenerated(t, dir, "items.ts")
+	assert.Contains(t, stub, "export type ItemsParams = {\n  id: string;\n}")
+	assert.Contains(t, stub, "params: ItemsParams,")
+	assert.Contains(t, stub, "cookies: ItemsRequestCookies,")
+
+	// the error handlers write an error body on every route, so it is documented even without WithErrors.
+	doc := readGenerated(t, dir, "swagger.json")
+	assert.Contains(t, doc, `"ErrorMessage": {`)
+	assert.Contains(t, doc, `"ErrorCode": {`)
+	assert.Contains(t, readGenerated(t, dir, "common.ts"), "export type ErrorCode =")
+
+	client := readGenerated(t, dir, "client.ts")
+	assert.Contains(t, client, `import type { ErrorMessage } from "./common"`)
+	assert.Contains(t, client, "type ErrorType = ErrorMessage\n")
+	assert.Contains(t, client, `import type { Items } from "./items"`)
+	assert.Contains(t, client, "export function createClient(options: ClientOptions = {}) {")
+	assert.Contains(t, client, "export class ApiError<")
+	assert.Contains(t, client, `    putItemsById: (input: RequestOf<Items>, init?: RequestInit) =>
+      request<Items>({ method: "PUT", path: "/items/{id}", requestContentType: "application/json", `+
+		`responseContentType: "application/json" }, input, init),`)
+	assert.Contains(t, client, `    getTest: (input: RequestOf<Test>, init?: RequestInit) =>`)
+
+	// fetch cannot set the Cookie header, so cookies are left to the browser.
+	assert.Contains(t, client, `"body" | "query" | "params" | "headers">>;`)
+	assert.NotContains(t, client, `"Cookie"`)
+	assert.Contains(t, client, "credentials: options.credentials,")
+}
+
+func TestCodeGenStubNames(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	_, err := oapi.GET(ech, "/items/:id", HandlerHeader)
+	require.NoError(t, err)
+
+	_, err = oapi.GET(ech, "/items/:id/tags", HandlerTest1)
+	require.NoError(t, err)
+
+	require.NoError(t, oapi.Flush(ech))
+
+	dir := t.TempDir()
+	require.NoError(t, oapi.CodeGen(dir))
+
+	// stubs are named after the last path segment that is not a parameter, while client functions use the whole path.
+	stub := readGenerated(t, dir, "items.ts")
+	assert.Contains(t, stub, "export type ItemsParams = {\n  id: string;\n}")
+	assert.Contains(t, stub, "export type TagsResponse = {\n  name: string;\n}")
+
+	client := readGenerated(t, dir, "client.ts")
+	assert.Contains(t, client, `import type { Items, Tags } from "./items"`)
+	assert.Contains(t, client, `    getItemsById: (input: RequestOf<Items>, init?: RequestInit) =>
+      request<Items>({ method: "GET", path: "/items/{id}"`)
+	assert.Contains(t, client, `    getItemsByIdTags: (input: RequestOf<Tags>, init?: RequestInit) =>
+      request<Tags>({ method: "GET", path: "/items/{id}/tags"`)
+}
+
+// checkGeneratedTS fails if a generated file declares a type twice, and type checks the files when tsc is installed.
+func checkGeneratedTS(t *testing.T, dir string) {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.ts"))
+	require.NoError(t, err)
+
+	declaration := regexp.MustCompile(`(?m)^export type (\w+)`)
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+
+		declared := map[string]bool{}
+
+		for _, match := range declaration.FindAllStringSubmatch(string(content), -1) {
+			assert.False(t, declared[match[1]], "%s declares %s twice", filepath.Base(file), match[1])
+			declared[match[1]] = true
+		}
+	}
+
+	tsc, err := exec.LookPath("tsc")
+	if err != nil {
+		return
+	}
+
+	args := append([]string{"--noEmit", "--strict", "--target", "es2022", "--lib", "es2022,dom"}, files...)
+	output, err := exec.Command(tsc, args...).CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+type testRemoveInput struct {
+	ID string `in:"path" json:"id"`
+}
+
+func HandlerRemove(inp testRemoveInput, ctx vctx.Context) (testOutput1, error) {
+	return testOutput1{Name: inp.ID}, nil
+}
+
+func TestCodeGenSharedPath(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	_, err := oapi.GET(ech, "/items", HandlerTest1)
+	require.NoError(t, err)
+
+	_, err = oapi.POST(ech, "/items", HandlerDecode, rpc.WithRequestContentType(echo.MIMEApplicationJSON))
+	require.NoError(t, err)
+
+	_, err = oapi.GET(ech, "/items/:id", HandlerHeader)
+	require.NoError(t, err)
+
+	_, err = oapi.DELETE(ech, "/items/:id", HandlerRemove)
+	require.NoError(t, err)
+
+	_, err = oapi.GET(ech, "/admin/items", HandlerTest1)
+	require.NoError(t, err)
+
+	_, err = oapi.GET(ech, "/shop/items", HandlerTest1)
+	require.NoError(t, err)
+
+	require.NoError(t, oapi.Flush(ech))
+
+	dir := t.TempDir()
+	require.NoError(t, oapi.CodeGen(dir))
+	checkGeneratedTS(t, dir)
+
+	// routes that share a stub name within their file are told apart by their method, then by their whole path.
+	stub := readGenerated(t, dir, "items.ts")
+	assert.Contains(t, stub, "export type GetItems = {")
+	assert.Contains(t, stub, "export type PostItemsRequestBody = {")
+	assert.Contains(t, stub, "export type GetItemsByIdParams = {\n  id: string;\n}")
+	assert.Contains(t, stub, "  query: GetItemsRequest,\n")
+
+	// routes without query parameters can leave the query out.
+	assert.Contains(t, stub, "  query?: DeleteItemsRequest,\n")
+	assert.Contains(t, readGenerated(t, dir, "admin.ts"), "export type Items = {")
+	assert.Contains(t, readGenerated(t, dir, "shop.ts"), "export type Items = {")
+
+	// stubs of other files that share a name are imported under the name of their client function.
+	client := readGenerated(t, dir, "client.ts")
+	assert.Contains(t, client, `import type { Items } from "./admin"`)
+	assert.Contains(t, client, `import type { Items as GetShopItems } from "./shop"`)
+	assert.Contains(t, client, `import type { GetItems, PostItems, DeleteItems, GetItemsById } from "./items"`)
+	assert.Contains(t, client, `    getItems: (input: RequestOf<GetItems>, init?: RequestInit) =>`)
+	assert.Contains(t, client, `    postItems: (input: RequestOf<PostItems>, init?: RequestInit) =>`)
+	assert.Contains(t, client, `    getAdminItems: (input: RequestOf<Items>, init?: RequestInit) =>`)
+	assert.Contains(t, client, `    getShopItems: (input: RequestOf<GetShopItems>, init?: RequestInit) =>`)
+}
+
+func TestCodeGenComponents(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	_, err := oapi.POST(ech, "/decode", HandlerDecode, rpc.WithRequestContentType(echo.MIMEApplicationJSON))
+	require.NoError(t, err)
+
+	_, err = oapi.PUT(ech, "/decode", HandlerDecode, rpc.WithRequestContentType(echo.MIMEApplicationJSON))
+	require.NoError(t, err)
+
+	require.NoError(t, oapi.Flush(ech))
+
+	dir := t.TempDir()
+	require.NoError(t, oapi.CodeGen(dir))
+
+	doc := readGenerated(t, dir, "swagger.json")
+	assert.Contains(t, doc, `"$ref": "#/components/schemas/testDecodeItem"`)
+	assert.Equal(t, 1, strings.Count(doc, `"title": "price"`))
+
+	common := readGenerated(t, dir, "common.ts")
+	assert.Contains(t, common, "export type testDecodeItem = {\n  price: number;\n}")
+
+	stub := readGenerated(t, dir, "decode.ts")
+	assert.Contains(t, stub, `import type { testDecodeItem } from "./common"`)
+	assert.Contains(t, stub, "items: testDecodeItem[];")
+}
@@ -0,0 +1,250 @@
+package rpc
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+//go:embed docsui/*.html docsui/assets
+var docsUIFiles embed.FS
+
+// DocsUI is a user interface for browsing an OpenAPI document.
+type DocsUI int
+
+const (
+	SwaggerUI DocsUI = iota
+	Redoc
+	Scalar
+)
+
+func (d DocsUI) page() string {
+	switch d {
+	case Redoc:
+		return "redoc.html"
+	case Scalar:
+		return "scalar.html"
+	default:
+		return "swagger.html"
+	}
+}
+
+// bundles returns the files of the user interface under docsui/assets/vendor, where docsui/fetch.sh vendors them.
+func (d DocsUI) bundles() []string {
+	switch d {
+	case Redoc:
+		return []string{"redoc/redoc.standalone.js"}
+	case Scalar:
+		return []string{"scalar/standalone.js"}
+	default:
+		return []string{"swagger-ui/swagger-ui-bundle.js", "swagger-ui/swagger-ui.css"}
+	}
+}
+
+type DocsOption interface {
+	privateDocsOption()
+}
+
+type docsOptions struct {
+	prefix      string
+	ui          DocsUI
+	config      map[string]any
+	middlewares []echo.MiddlewareFunc
+	assets      fs.FS
+	bearerToken string
+}
+
+type withDocsPrefix struct {
+	prefix string
+}
+
+func (w withDocsPrefix) privateDocsOption() {}
+
+// WithDocsPrefix sets the path the docs are mounted at, which is /docs by default.
+func WithDocsPrefix(prefix string) withDocsPrefix {
+	return withDocsPrefix{prefix: prefix}
+}
+
+type withDocsUI struct {
+	ui DocsUI
+}
+
+func (w withDocsUI) privateDocsOption() {}
+
+// WithDocsUI sets the user interface of the docs, which is SwaggerUI by default.
+func WithDocsUI(ui DocsUI) withDocsUI {
+	return withDocsUI{ui: ui}
+}
+
+type withDocsConfig struct {
+	config map[string]any
+}
+
+func (w withDocsConfig) privateDocsOption() {}
+
+// WithDocsConfig sets options of the user interface, e.g. {"docExpansion": "none"} for SwaggerUI, which are passed
+// to it as is and override the defaults.
+func WithDocsConfig(config map[string]any) withDocsConfig {
+	return withDocsConfig{config: config}
+}
+
+type withDocsMiddleware struct {
+	middlewares []echo.MiddlewareFunc
+}
+
+func (w withDocsMiddleware) privateDocsOption() {}
+
+// WithDocsMiddleware puts the docs, including the document and the assets, behind middlewares such as auth.
+func WithDocsMiddleware(middlewares ...echo.MiddlewareFunc) withDocsMiddleware {
+	return withDocsMiddleware{middlewares: middlewares}
+}
+
+type withDocsAssets struct {
+	assets fs.FS
+}
+
+func (w withDocsAssets) privateDocsOption() {}
+
+// WithDocsAssets serves the bundles of the user interfaces from assets, laid out as docsui/fetch.sh downloads them,
+// instead of the embedded ones, e.g. to use other versions of the user interfaces.
+func WithDocsAssets(assets fs.FS) withDocsAssets {
+	return withDocsAssets{assets: assets}
+}
+
+type withDocsBearerToken struct {
+	token string
+}
+
+func (w withDocsBearerToken) privateDocsOption() {}
+
+// WithDocsBearerToken fills in token for BearerSecurityScheme in SwaggerUI, so that requests are authorized from the
+// start during development.
+func WithDocsBearerToken(token string) withDocsBearerToken {
+	return withDocsBearerToken{token: token}
+}
+
+var errMissingDocsAsset = errors.New("missing docs asset")
+
+// ServeDocs serves a user interface for spec at the docs prefix, along with spec at openapi.json under the prefix.
+// The pages and the vendored bundles of the user interfaces are embedded, so that the docs work offline and under a
+// CSP that only allows 'self'. Nothing is loaded from a CDN, so a user interface whose bundles are not vendored in
+// docsui/assets/vendor is an error, unless they are given with WithDocsAssets.
+func ServeDocs(ech EchoInterface, title string, spec []byte, options ...DocsOption) error { //nolint:funlen
+	//nolint:exhaustruct
+	opts := docsOptions{prefix: "/docs", ui: SwaggerUI}
+
+	for _, option := range options {
+		switch opt := option.(type) {
+		case withDocsPrefix:
+			opts.prefix = opt.prefix
+		case withDocsUI:
+			opts.ui = opt.ui
+		case withDocsConfig:
+			opts.config = opt.config
+		case withDocsMiddleware:
+			opts.middlewares = append(opts.middlewares, opt.middlewares...)
+		case withDocsAssets:
+			opts.assets = opt.assets
+		case withDocsBearerToken:
+			opts.bearerToken = opt.token
+		}
+	}
+
+	if opts.config == nil {
+		opts.config = map[string]any{}
+	}
+
+	pages, err := fs.Sub(docsUIFiles, "docsui")
+	if err != nil {
+		return fmt.Errorf("failed to open embedded docs pages: %w", err)
+	}
+
+	// only the assets are served, so that the templates of the pages are not.
+	assets, err := fs.Sub(docsUIFiles, "docsui/assets")
+	if err != nil {
+		return fmt.Errorf("failed to open embedded docs assets: %w", err)
+	}
+
+	vendor := opts.assets
+	if vendor == nil {
+		vendor, err = fs.Sub(assets, "vendor")
+		if err != nil {
+			return fmt.Errorf("failed to open embedded docs bundles: %w", err)
+		}
+	}
+
+	page, err := template.ParseFS(pages, opts.ui.page())
+	if err != nil {
+		return fmt.Errorf("failed to parse docs page: %w", err)
+	}
+
+	prefix := strings.TrimSuffix(opts.prefix, "/")
+	specURL := prefix + "/openapi.json"
+
+	bundles := map[string]string{}
+
+	for _, bundle := range opts.ui.bundles() {
+		if _, err := fs.Stat(vendor, bundle); err != nil {
+			if opts.assets == nil {
+				return fmt.Errorf("%s is not vendored, run docsui/fetch.sh or use WithDocsAssets: %w", bundle,
+					errors.Join(err, errMissingDocsAsset))
+			}
+
+			return fmt.Errorf("%s: %w", bundle, errors.Join(err, errMissingDocsAsset))
+		}
+
+		bundles[bundle] = prefix + "/assets/vendor/" + bundle
+	}
+
+	config := map[string]any{
+		"specUrl":      specURL,
+		"options":      opts.config,
+		"bearerScheme": BearerSecurityScheme,
+		"bearerToken":  opts.bearerToken,
+	}
+
+	configJSON, err := json.Marshal(opts.config)
+	if err != nil {
+		return fmt.Errorf("failed to encode docs config: %w", err)
+	}
+
+	var html strings.Builder
+
+	if err := page.Execute(&html, map[string]any{
+		"Title":      title,
+		"Assets":     prefix + "/assets",
+		"Bundles":    bundles,
+		"SpecURL":    specURL,
+		"Config":     config,
+		"ConfigJSON": string(configJSON),
+	}); err != nil {
+		return fmt.Errorf("failed to render docs page: %w", err)
+	}
+
+	rendered := html.String()
+
+	pageHandler := func(c echo.Context) error {
+		return c.HTML(http.StatusOK, rendered)
+	}
+
+	ech.Add(http.MethodGet, prefix, pageHandler, opts.middlewares...)
+	ech.Add(http.MethodGet, prefix+"/", pageHandler, opts.middlewares...)
+	ech.Add(http.MethodGet, specURL, func(c echo.Context) error {
+		return c.JSONBlob(http.StatusOK, spec)
+	}, opts.middlewares...)
+	ech.Add(http.MethodGet, prefix+"/assets/*", echo.WrapHandler(
+		http.StripPrefix(prefix+"/assets/", http.FileServer(http.FS(assets))),
+	), opts.middlewares...)
+	ech.Add(http.MethodGet, prefix+"/assets/vendor/*", echo.WrapHandler(
+		http.StripPrefix(prefix+"/assets/vendor/", http.FileServer(http.FS(vendor))),
+	), opts.middlewares...)
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package rpc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DimmyJing/valise/rpc"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func docsAssets(files ...string) fstest.MapFS {
+	assets := fstest.MapFS{}
+
+	for _, name := range files {
+		assets[name] = &fstest.MapFile{Data: []byte("/* " + name + " */")}
+	}
+
+	return assets
+}
+
+func TestServeDocs(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	ech := echo.New()
+	requireKey := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Header.Get("X-Docs-Key") != "secret" {
+				return c.NoContent(http.StatusUnauthorized)
+			}
+
+			return next(c)
+		}
+	}
+
+	err := rpc.ServeDocs(ech, "Test API", []byte(`{"openapi":"3.1.0"}`),
+		rpc.WithDocsPrefix("/api/docs/"),
+		rpc.WithDocsUI(rpc.Redoc),
+		rpc.WithDocsConfig(map[string]any{"hideDownloadButton": true}),
+		rpc.WithDocsMiddleware(requireKey),
+		rpc.WithDocsAssets(docsAssets("redoc/redoc.standalone.js")),
+	)
+	require.NoError(t, err)
+
+	get := func(path string, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Docs-Key", key)
+		rec := httptest.NewRecorder()
+		ech.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, get("/api/docs/", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, get("/api/docs/openapi.json", "").Code)
+
+	rec := get("/api/docs/", "secret")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `<title>Test API</title>`)
+	assert.Contains(t, rec.Body.String(), `<script src="/api/docs/assets/vendor/redoc/redoc.standalone.js"></script>`)
+	assert.Contains(t, rec.Body.String(), `"specUrl":"/api/docs/openapi.json"`)
+	assert.Contains(t, rec.Body.String(), `"options":{"hideDownloadButton":true}`)
+	assert.NotContains(t, rec.Body.String(), "https://")
+
+	assert.Equal(t, http.StatusOK, get("/api/docs", "secret").Code)
+
+	rec = get("/api/docs/openapi.json", "secret")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"openapi":"3.1.0"}`, rec.Body.String())
+
+	rec = get("/api/docs/assets/vendor/redoc/redoc.standalone.js", "secret")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "/* redoc/redoc.standalone.js */", rec.Body.String())
+
+	rec = get("/api/docs/assets/redoc-init.js", "secret")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Redoc.init")
+
+	assert.Equal(t, http.StatusNotFound, get("/api/docs/assets/redoc.html", "secret").Code)
+	assert.Equal(t, http.StatusNotFound, get("/api/docs/assets/vendor/swagger-ui/swagger-ui.css", "secret").Code)
+
+	err = rpc.ServeDocs(echo.New(), "Test API", nil, rpc.WithDocsAssets(docsAssets()))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "swagger-ui/swagger-ui-bundle.js")
+}
+
+func TestServeDocsDefault(t *testing.T) {
+	t.Parallel()
+
+	bundles := map[rpc.DocsUI]string{
+		rpc.SwaggerUI: "swagger-ui/swagger-ui-bundle.js",
+		rpc.Redoc:     "redoc/redoc.standalone.js",
+		rpc.Scalar:    "scalar/standalone.js",
+	}
+
+	for docsUI, bundle := range bundles {
+		ech := echo.New()
+		err := rpc.ServeDocs(ech, "Test API", []byte(`{"openapi":"3.1.0"}`), rpc.WithDocsUI(docsUI))
+
+		// nothing is loaded from a CDN, so the user interfaces that docsui/fetch.sh has not vendored fail.
+		if _, statErr := os.Stat(filepath.Join("docsui", "assets", "vendor", bundle)); statErr != nil {
+			require.Error(t, err, bundle)
+			assert.Contains(t, err.Error(), bundle+" is not vendored")
+
+			continue
+		}
+
+		require.NoError(t, err, bundle)
+
+		rec := httptest.NewRecorder()
+		ech.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "https://")
+
+		// every file the page loads is served by the docs.
+		urls := regexp.MustCompile(`(?:src|href)="([^"]+)"`).FindAllStringSubmatch(rec.Body.String(), -1)
+		require.NotEmpty(t, urls)
+
+		for _, match := range urls {
+			rec := httptest.NewRecorder()
+			ech.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, match[1], nil))
+			assert.Equal(t, http.StatusOK, rec.Code, match[1])
+			assert.NotEmpty(t, rec.Body.String(), match[1])
+		}
+	}
+}
+
+func TestServeDocsVendored(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	require.NoError(t, rpc.ServeDocs(ech, "Test API", []byte(`{"openapi":"3.1.0"}`)))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		ech.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return rec
+	}
+
+	rec := get("/docs/")
+	assert.Contains(t, rec.Body.String(), `<script src="/docs/assets/vendor/swagger-ui/swagger-ui-bundle.js"></script>`)
+	assert.Contains(t, rec.Body.String(), `<link rel="stylesheet" href="/docs/assets/vendor/swagger-ui/swagger-ui.css">`)
+	assert.NotContains(t, rec.Body.String(), "https://")
+
+	rec = get("/docs/assets/vendor/swagger-ui/swagger-ui-bundle.js")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "SwaggerUIBundle")
+
+	for _, page := range []string{"swagger.html", "redoc.html", "scalar.html"} {
+		assert.Equal(t, http.StatusNotFound, get("/docs/assets/"+page).Code, page)
+	}
+}
+
+func TestServeSwaggerUI(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	rpc.ServeSwaggerUI(ech, "Test API", []byte(`{"openapi":"3.1.0"}`), "user")
+
+	for _, path := range []string{"/swagger/", "/swagger/swagger.json", "/swagger/openapi.json"} {
+		rec := httptest.NewRecorder()
+		ech.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+	}
+}
@@ -0,0 +1,4 @@
+// the configuration is read from a json block rather than inlined, so that the page works under a strict CSP.
+var config = JSON.parse(document.getElementById("docs-config").textContent);
+
+Redoc.init(config.specUrl, config.options || {}, document.getElementById("docs"));
@@ -0,0 +1,22 @@
+// the configuration is read from a json block rather than inlined, so that the page works under a strict CSP.
+var config = JSON.parse(document.getElementById("docs-config").textContent);
+
+window.ui = SwaggerUIBundle(Object.assign({
+  url: config.specUrl,
+  dom_id: "#docs",
+  docExpansion: "list",
+  deepLinking: true,
+  filter: true,
+  tryItOutEnabled: true,
+  showMutatedRequest: false,
+  requestSnippetsEnabled: true,
+  requestSnippets: { defaultExpanded: false },
+  persistAuthorization: true,
+  presets: [SwaggerUIBundle.presets.apis],
+  plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+  onComplete: function () {
+    if (config.bearerToken) {
+      window.ui.preauthorizeApiKey(config.bearerScheme, config.bearerToken);
+    }
+  },
+}, config.options));
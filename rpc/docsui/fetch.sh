@@ -0,0 +1,24 @@
+#!/bin/sh
+# Downloads the pinned ui bundles into assets/vendor, where they are embedded and served by ServeDocs, or into the
+# given directory, to be passed to WithDocsAssets. ServeDocs fails for a user interface that is not vendored.
+set -eu
+
+DIR=${1:-$(dirname "$0")/assets/vendor}
+
+SWAGGER_UI_VERSION=5.18.2
+REDOC_VERSION=2.1.5
+SCALAR_VERSION=1.24.0
+
+fetch() {
+	mkdir -p "$(dirname "$DIR/$2")"
+	curl -fsSL "https://cdn.jsdelivr.net/npm/$1" -o "$DIR/$2"
+}
+
+fetch "swagger-ui-dist@$SWAGGER_UI_VERSION/swagger-ui-bundle.js" swagger-ui/swagger-ui-bundle.js
+fetch "swagger-ui-dist@$SWAGGER_UI_VERSION/swagger-ui-bundle.js.LICENSE.txt" swagger-ui/swagger-ui-bundle.js.LICENSE.txt
+fetch "swagger-ui-dist@$SWAGGER_UI_VERSION/swagger-ui.css" swagger-ui/swagger-ui.css
+fetch "swagger-ui-dist@$SWAGGER_UI_VERSION/LICENSE" swagger-ui/LICENSE
+fetch "redoc@$REDOC_VERSION/bundles/redoc.standalone.js" redoc/redoc.standalone.js
+fetch "redoc@$REDOC_VERSION/LICENSE" redoc/LICENSE
+fetch "@scalar/api-reference@$SCALAR_VERSION/dist/browser/standalone.js" scalar/standalone.js
+fetch "@scalar/api-reference@$SCALAR_VERSION/LICENSE" scalar/LICENSE
@@ -0,0 +1,92 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/DimmyJing/valise/jsonschema"
+	"github.com/labstack/echo/v4"
+)
+
+type registeredError struct {
+	err     error
+	status  int
+	code    string
+	message string
+}
+
+//nolint:gochecknoglobals
+var (
+	errorRegistryMu sync.RWMutex
+	errorRegistry   []registeredError
+)
+
+// RegisterError maps err, and every error wrapping it, to an HTTP status and a public error code and message.
+// Registering the same error again replaces its previous registration.
+func RegisterError(err error, status int, code string, message string) {
+	errorRegistryMu.Lock()
+	defer errorRegistryMu.Unlock()
+
+	registered := registeredError{err: err, status: status, code: code, message: message}
+
+	for i, existing := range errorRegistry {
+		if existing.err == err { //nolint:errorlint
+			errorRegistry[i] = registered
+
+			return
+		}
+	}
+
+	errorRegistry = append(errorRegistry, registered)
+}
+
+func lookupError(err error) (registeredError, bool) {
+	errorRegistryMu.RLock()
+	defer errorRegistryMu.RUnlock()
+
+	for _, registered := range errorRegistry {
+		if errors.Is(err, registered.err) {
+			return registered, true
+		}
+	}
+
+	//nolint:exhaustruct
+	return registeredError{}, false
+}
+
+func (r registeredError) httpError(err error) *echo.HTTPError {
+	return NewHTTPError(r.status, r.message, r.code).SetInternal(err)
+}
+
+func errorCodeSchema() *jsonschema.JSONSchema {
+	errorRegistryMu.RLock()
+	defer errorRegistryMu.RUnlock()
+
+	codes := []string{ValidationErrorCode, DecodeErrorCode}
+	descriptions := []string{
+		fmt.Sprintf("%s (%d): request validation failed", ValidationErrorCode, http.StatusBadRequest),
+		fmt.Sprintf("%s (%d): request decoding failed", DecodeErrorCode, http.StatusBadRequest),
+	}
+
+	for _, registered := range errorRegistry {
+		if slices.Contains(codes, registered.code) {
+			continue
+		}
+
+		codes = append(codes, registered.code)
+		descriptions = append(descriptions,
+			fmt.Sprintf("%s (%d): %s", registered.code, registered.status, registered.message))
+	}
+
+	//nolint:exhaustruct
+	return &jsonschema.JSONSchema{
+		Title:       errorCodeName,
+		Description: strings.Join(descriptions, "\n"),
+		Type:        "string",
+		Enums:       codes,
+	}
+}
@@ -0,0 +1,99 @@
+package rpc_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DimmyJing/valise/rpc"
+	"github.com/DimmyJing/valise/vctx"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTestItemNotFound = errors.New("item not found")
+
+func HandlerRegisteredError(inp testInput1, ctx vctx.Context) (testOutput1, error) {
+	return testOutput1{Name: ""}, fmt.Errorf("loading item %s: %w", inp.Name, errTestItemNotFound)
+}
+
+func TestRegisteredError(t *testing.T) {
+	t.Parallel()
+
+	rpc.RegisterError(errTestItemNotFound, http.StatusNotFound, "item_not_found", "item not found")
+
+	ech := echo.New()
+	ech.HTTPErrorHandler = rpc.HTTPErrorHandler
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	_, err := oapi.GET(ech, "/item", HandlerRegisteredError, rpc.WithErrors(http.StatusNotFound))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/item?name=a", nil)
+	rec := httptest.NewRecorder()
+	ech.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"code":"item_not_found","message":"item not found"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	rpc.HTTPErrorHandler(fmt.Errorf("wrapped: %w", errTestItemNotFound), ech.NewContext(req, rec))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"code":"item_not_found","message":"item not found"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	rpc.HTTPErrorHandler(errors.New("internal"), ech.NewContext(req, rec)) //nolint:goerr113
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{}`, rec.Body.String())
+
+	require.NoError(t, oapi.Flush(ech))
+
+	dir := t.TempDir()
+	require.NoError(t, oapi.CodeGen(dir))
+
+	assert.Contains(t, readGenerated(t, dir, "swagger.json"), `"item_not_found"`)
+	assert.Contains(t, readGenerated(t, dir, "common.ts"), `"item_not_found"`)
+	assert.Contains(t, readGenerated(t, dir, "common.ts"), `export type ErrorCode = "validation_error" | "decode_error"`)
+}
+
+func TestUndeclaredErrors(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	ech.HTTPErrorHandler = rpc.HTTPErrorHandler
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	_, err := oapi.POST(ech, "/validate", HandlerValidate, rpc.WithRequestContentType(echo.MIMEApplicationJSON))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(`{"name":"ab","email":"ab"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ech.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"validation_error"`)
+
+	require.NoError(t, oapi.Flush(ech))
+
+	docJSON, err := oapi.Document()
+	require.NoError(t, err)
+
+	var doc struct {
+		Paths      map[string]map[string]struct{ Responses map[string]any }
+		Components struct{ Schemas map[string]any }
+	}
+
+	require.NoError(t, json.Unmarshal(docJSON, &doc))
+
+	// the error handlers write an error body on routes that declare no errors too, so it is always documented.
+	assert.Contains(t, doc.Components.Schemas, "ErrorMessage")
+	assert.Contains(t, doc.Components.Schemas, "ErrorCode")
+	assert.Len(t, doc.Paths["/validate"]["post"].Responses, 1)
+	assert.Contains(t, doc.Paths["/validate"]["post"].Responses, "200")
+}
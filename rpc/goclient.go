@@ -0,0 +1,179 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/DimmyJing/valise/attr"
+	"github.com/DimmyJing/valise/log"
+)
+
+var errUnsupportedClientType = errors.New("unsupported client type")
+
+type goImports struct {
+	aliases map[string]string
+}
+
+func (g *goImports) alias(pkgPath string) string {
+	if alias, found := g.aliases[pkgPath]; found {
+		return alias
+	}
+
+	base := strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' {
+			return '_'
+		}
+
+		return r
+	}, filepath.Base(pkgPath))
+
+	alias := base
+	for idx := 2; slices.Contains(g.usedAliases(), alias) || alias == "context" || alias == "rpc"; idx++ {
+		alias = base + strconv.Itoa(idx)
+	}
+
+	g.aliases[pkgPath] = alias
+
+	return alias
+}
+
+func (g *goImports) usedAliases() []string {
+	result := make([]string, 0, len(g.aliases))
+	for _, alias := range g.aliases {
+		result = append(result, alias)
+	}
+
+	return result
+}
+
+func (g *goImports) typeExpr(typ reflect.Type) (string, error) {
+	if typ.Name() != "" {
+		if typ.PkgPath() == "" {
+			return typ.Name(), nil
+		}
+
+		if !token.IsExported(typ.Name()) || strings.Contains(typ.Name(), "[") {
+			return "", fmt.Errorf("type %s cannot be referenced from another package: %w", typ, errUnsupportedClientType)
+		}
+
+		return g.alias(typ.PkgPath()) + "." + typ.Name(), nil
+	}
+
+	//nolint:exhaustive
+	switch typ.Kind() {
+	case reflect.Slice:
+		elem, err := g.typeExpr(typ.Elem())
+
+		return "[]" + elem, err
+	case reflect.Pointer:
+		elem, err := g.typeExpr(typ.Elem())
+
+		return "*" + elem, err
+	default:
+		return "", fmt.Errorf("anonymous type %s: %w", typ, errUnsupportedClientType)
+	}
+}
+
+// GoClientGen writes a go package to path with a method per rpc route, which reuses the input and output types of
+// the handlers. Stream and WebSocket routes are skipped, and so are routes whose types cannot be referenced from
+// another package, with a warning. Flush must be called first.
+func (o *OpenAPI) GoClientGen(path string, pkgName string) error { //nolint:funlen
+	imports := &goImports{aliases: map[string]string{}}
+
+	var methods strings.Builder
+
+	for pair := o.document.Paths.Oldest(); pair != nil; pair = pair.Next() {
+		routePath, pathItem := pair.Key, pair.Value
+
+		methodNames := make([]string, 0, len(pathItem))
+		for method := range pathItem {
+			methodNames = append(methodNames, method)
+		}
+
+		slices.Sort(methodNames)
+
+		for _, method := range methodNames {
+			operation := pathItem[method]
+			if operation.inputType == nil || operation.outputType == nil {
+				continue
+			}
+
+			aliases := maps.Clone(imports.aliases)
+			inputExpr, inputErr := imports.typeExpr(operation.inputType)
+			outputExpr, outputErr := imports.typeExpr(operation.outputType)
+
+			if err := errors.Join(inputErr, outputErr); err != nil {
+				// drop the imports of the skipped route, which would be unused otherwise.
+				imports.aliases = aliases
+
+				log.Warn("skipping route in go client",
+					attr.String("method", strings.ToUpper(method)),
+					attr.String("path", routePath),
+					attr.String("error", err.Error()))
+
+				continue
+			}
+
+			funcName := clientFunctionName(method, routePath)
+			funcName = strings.ToUpper(funcName[:1]) + funcName[1:]
+			upperMethod := strings.ToUpper(method)
+
+			fmt.Fprintf(&methods, "\n// %s calls %s %s.\n", funcName, upperMethod, routePath)
+			fmt.Fprintf(&methods, "func (c *Client) %s(ctx context.Context, input %s) (%s, error) {\n",
+				funcName, inputExpr, outputExpr)
+			fmt.Fprintf(&methods, "\tvar output %s\n\n", outputExpr)
+			fmt.Fprintf(&methods, "\terr := c.Do(ctx, %q, %q, %q, input, &output)\n\n",
+				upperMethod, routePath, requestContentType(operation))
+			methods.WriteString("\treturn output, err\n}\n")
+		}
+	}
+
+	pkgPaths := make([]string, 0, len(imports.aliases))
+	for pkgPath := range imports.aliases {
+		pkgPaths = append(pkgPaths, pkgPath)
+	}
+
+	slices.Sort(pkgPaths)
+
+	var source strings.Builder
+
+	source.WriteString("// Code generated by valise. DO NOT EDIT.\n\npackage " + pkgName + "\n\n")
+	source.WriteString("import (\n\t\"context\"\n\n\t\"github.com/DimmyJing/valise/rpc\"\n")
+
+	for _, pkgPath := range pkgPaths {
+		fmt.Fprintf(&source, "\t%s %q\n", imports.aliases[pkgPath], pkgPath)
+	}
+
+	source.WriteString(")\n\ntype Client struct {\n\t*rpc.Client\n}\n\n")
+	source.WriteString("func New(baseURL string, options ...rpc.ClientOption) *Client {\n")
+	source.WriteString("\treturn &Client{Client: rpc.NewClient(baseURL, options...)}\n}\n")
+	source.WriteString(methods.String())
+
+	formatted, err := format.Source([]byte(source.String()))
+	if err != nil {
+		return fmt.Errorf("failed to format go client: %w", err)
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		//nolint:mnd
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			return fmt.Errorf("error creating directory: %w", err)
+		}
+	}
+
+	//nolint:gosec,mnd
+	if err := os.WriteFile(filepath.Join(path, "client.go"), formatted, 0o644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
@@ -9,7 +9,7 @@ import (
 	"net/http"
 	"reflect"
 	"slices"
-	"strings"
+	"time"
 
 	"github.com/DimmyJing/valise/jsonschema"
 	"github.com/DimmyJing/valise/vctx"
@@ -57,11 +57,13 @@ func isRPCHandler(handler any) (reflect.Type, reflect.Type, bool) {
 }
 
 type inputFieldAttrs struct {
-	isList  bool
-	isBytes bool
-	typ     reflect.Type
-	inPath  bool
-	inQuery bool
+	isList   bool
+	isBytes  bool
+	typ      reflect.Type
+	inPath   bool
+	inQuery  bool
+	inHeader bool
+	inCookie bool
 }
 
 var errInvalidTag = errors.New("invalid in tag")
@@ -69,31 +71,26 @@ var errInvalidTag = errors.New("invalid in tag")
 func getInputFieldAttrs(inputType reflect.Type, hasBody bool) (map[string]inputFieldAttrs, error) { //nolint:cyclop
 	inputFieldAttrsMap := map[string]inputFieldAttrs{}
 
-	for i := range inputType.NumField() {
-		field := inputType.Field(i)
-		if !field.IsExported() {
-			continue
+	for _, field := range jsonschema.StructFields(inputType) {
+		fieldAttrs := inputFieldAttrs{
+			typ:      field.Type,
+			isList:   false,
+			inPath:   false,
+			inQuery:  !hasBody,
+			inHeader: false,
+			inCookie: false,
+			isBytes:  false,
 		}
+		fieldName := field.JSONName
 
-		fieldAttrs := inputFieldAttrs{typ: field.Type, isList: false, inPath: false, inQuery: !hasBody, isBytes: false}
-		fieldName := strings.ToLower(string(field.Name[0])) + field.Name[1:]
+		// text types such as uuid.UUID or net.IP are single strings even though they are arrays or slices.
+		isText := jsonschema.IsTextType(field.Type)
 
-		if jsonTag, found := field.Tag.Lookup("json"); found {
-			splitTags := strings.Split(jsonTag, ",")
-			if len(splitTags) > 0 {
-				if splitTags[0] == "-" && len(splitTags) == 1 {
-					continue
-				} else if splitTags[0] != "" {
-					fieldName = splitTags[0]
-				}
-			}
-		}
-
-		if field.Type.Kind() == reflect.Slice || field.Type.Kind() == reflect.Array {
+		if (field.Type.Kind() == reflect.Slice || field.Type.Kind() == reflect.Array) && !isText {
 			fieldAttrs.isList = true
 		}
 
-		if field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Uint8 {
+		if field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Uint8 && !isText {
 			fieldAttrs.isBytes = true
 		}
 
@@ -108,6 +105,16 @@ func getInputFieldAttrs(inputType reflect.Type, hasBody bool) (map[string]inputF
 				}
 			case "query":
 				fieldAttrs.inQuery = true
+			case "header":
+				fieldAttrs.inHeader = true
+				fieldAttrs.inQuery = false
+			case "cookie":
+				fieldAttrs.inCookie = true
+				fieldAttrs.inQuery = false
+
+				if fieldAttrs.isList {
+					return nil, fmt.Errorf("cannot use in:cookie on list field %s: %w", fieldName, errInvalidTag)
+				}
 			default:
 				return nil, fmt.Errorf("invalid in tag %s: %w", inTag, errInvalidTag)
 			}
@@ -126,6 +133,7 @@ func parseInput( //nolint:funlen,gocognit,cyclop
 	echoCtx echo.Context,
 	ctx vctx.Context,
 	inputType reflect.Type,
+	strictDecoding bool,
 ) (reflect.Value, error) {
 	inputValue := reflect.New(inputType).Elem()
 
@@ -136,11 +144,14 @@ func parseInput( //nolint:funlen,gocognit,cyclop
 		if requestContentType == echo.MIMEApplicationJSON {
 			err := json.NewDecoder(echoCtx.Request().Body).Decode(&inputMap)
 			if err != nil {
-				return inputValue, ctx.Fail(fmt.Errorf("error decoding input json: %w", err))
+				return inputValue, ctx.Fail(&jsonschema.DecodeError{
+					Path: "",
+					Err:  fmt.Errorf("error decoding input json: %w", err),
+				})
 			}
 		} else if requestContentType == echo.MIMEMultipartForm || requestContentType == echo.MIMEApplicationForm {
 			for key, value := range inputFieldAttrsMap {
-				if value.inQuery || value.inPath {
+				if value.inQuery || value.inPath || value.inHeader || value.inCookie {
 					continue
 				}
 
@@ -197,10 +208,27 @@ func parseInput( //nolint:funlen,gocognit,cyclop
 			if param := echoCtx.Param(key); param != "" {
 				inputMap[key] = param
 			}
+		} else if value.inHeader {
+			if headerValues := echoCtx.Request().Header.Values(key); len(headerValues) > 0 {
+				if value.isList {
+					inputMap[key] = headerValues
+				} else {
+					inputMap[key] = headerValues[0]
+				}
+			}
+		} else if value.inCookie {
+			if cookie, err := echoCtx.Cookie(key); err == nil {
+				inputMap[key] = cookie.Value
+			}
 		}
 	}
 
-	err := jsonschema.AnyToValue(inputMap, inputValue)
+	decodeOptions := []jsonschema.DecodeOption{}
+	if !strictDecoding {
+		decodeOptions = append(decodeOptions, jsonschema.WithAllowUnknownFields())
+	}
+
+	err := jsonschema.AnyToValue(inputMap, inputValue, decodeOptions...)
 	if err != nil {
 		return inputValue, ctx.Fail(fmt.Errorf("error converting input %v: %w", inputMap, err))
 	}
@@ -208,12 +236,153 @@ func parseInput( //nolint:funlen,gocognit,cyclop
 	return inputValue, nil
 }
 
-func createRPCHandler( //nolint:funlen,cyclop,gocognit
+// bindInput parses and validates the input of a request, returning the http error to fail the request with.
+func bindInput(
+	inputFieldAttrsMap map[string]inputFieldAttrs,
+	hasBody bool,
+	echoCtx echo.Context,
+	ctx vctx.Context,
+	inputType reflect.Type,
+	opts routeOptions,
+) (reflect.Value, error) {
+	requestContentType := opts.requestContentType
+	if t, _, err := mime.ParseMediaType(echoCtx.Request().Header.Get("Content-Type")); err == nil {
+		requestContentType = t
+	}
+
+	inputValue, err := parseInput(
+		inputFieldAttrsMap,
+		hasBody,
+		requestContentType,
+		echoCtx,
+		ctx,
+		inputType,
+		opts.strictDecoding,
+	)
+	if err != nil {
+		var decodeErr *jsonschema.DecodeError
+		if errors.As(err, &decodeErr) {
+			return inputValue, ctx.Fail(NewDecodeHTTPError(decodeErr))
+		}
+
+		return inputValue, ctx.Fail(NewInternalHTTPError(http.StatusBadRequest, err))
+	}
+
+	if validationErrors := jsonschema.ValidateValue(inputValue); len(validationErrors) > 0 {
+		return inputValue, ctx.Fail(NewValidationHTTPError(validationErrors))
+	}
+
+	return inputValue, nil
+}
+
+// failHandler converts an error returned by a handler into the http error to fail the request with.
+func failHandler(ctx vctx.Context, err error) error {
+	var httpError *echo.HTTPError
+
+	if errors.As(err, &httpError) {
+		return err
+	} else if registered, found := lookupError(err); found {
+		return ctx.Fail(registered.httpError(err))
+	}
+
+	return ctx.Fail(NewInternalHTTPError(http.StatusInternalServerError, err))
+}
+
+type StatusCoder interface {
+	StatusCode() int
+}
+
+//nolint:gochecknoglobals
+var statusCoderInterface = reflect.TypeOf((*StatusCoder)(nil)).Elem()
+
+type outputFieldAttrs struct {
+	headers     map[string][]int
+	statusField []int
+	statusName  string
+}
+
+func getOutputFieldAttrs(outputType reflect.Type) (outputFieldAttrs, error) {
+	attrs := outputFieldAttrs{headers: map[string][]int{}, statusField: nil, statusName: ""}
+
+	if outputType.Kind() != reflect.Struct {
+		return attrs, nil
+	}
+
+	for _, field := range jsonschema.OutFields(outputType) {
+		fieldName := field.JSONName
+
+		switch outTag := field.Tag.Get("out"); outTag {
+		case "header":
+			attrs.headers[fieldName] = field.Index
+		case "status":
+			//nolint:exhaustive
+			switch field.Type.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			default:
+				return attrs, fmt.Errorf("out:status field %s must be an integer: %w", fieldName, errInvalidTag)
+			}
+
+			attrs.statusField = field.Index
+			attrs.statusName = fieldName
+		default:
+			return attrs, fmt.Errorf("invalid out tag %s: %w", outTag, errInvalidTag)
+		}
+	}
+
+	return attrs, nil
+}
+
+func defaultStatus(outputType reflect.Type, opts routeOptions) int {
+	if len(opts.statuses) > 0 {
+		return opts.statuses[0]
+	}
+
+	if outputType.Implements(statusCoderInterface) {
+		if coder, ok := reflect.Zero(outputType).Interface().(StatusCoder); ok && coder.StatusCode() != 0 {
+			return coder.StatusCode()
+		}
+	}
+
+	return http.StatusOK
+}
+
+func isNoContentStatus(status int) bool {
+	return status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified
+}
+
+func setResponseHeader(header http.Header, name string, value reflect.Value) error {
+	headerValue, err := jsonschema.ValueToAny(value)
+	if err != nil {
+		return fmt.Errorf("error converting header %s: %w", name, err)
+	}
+
+	switch val := headerValue.(type) {
+	case nil:
+	case string:
+		if val != "" {
+			header.Set(name, val)
+		}
+	case time.Time:
+		if !val.IsZero() {
+			header.Set(name, val.UTC().Format(http.TimeFormat))
+		}
+	case []any:
+		for _, elem := range val {
+			header.Add(name, fmt.Sprint(elem))
+		}
+	default:
+		header.Set(name, fmt.Sprint(val))
+	}
+
+	return nil
+}
+
+func createRPCHandler( //nolint:funlen,cyclop,gocognit,gocyclo
 	handler any,
 	method string,
 	inputType reflect.Type,
-	requestContentType string,
-	responseContentType string,
+	outputType reflect.Type,
+	opts routeOptions,
 	preHandlerHook func(vctx.Context, any) vctx.Context,
 	postHandlerHook func(vctx.Context, any, any),
 ) (echo.HandlerFunc, error) {
@@ -224,25 +393,29 @@ func createRPCHandler( //nolint:funlen,cyclop,gocognit
 		return nil, fmt.Errorf("failed to get input field attrs: %w", err)
 	}
 
+	outputAttrs, err := getOutputFieldAttrs(outputType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get output field attrs: %w", err)
+	}
+
 	handlerValue := reflect.ValueOf(handler)
+	responseContentType := opts.responseContentType
+	routeStatus := defaultStatus(outputType, opts)
 
-	return echo.HandlerFunc(func(echoCtx echo.Context) error {
-		ctx := FromEchoContext(echoCtx).ctx
+	var responseSchema *jsonschema.JSONSchema
 
-		if t, _, err := mime.ParseMediaType(echoCtx.Request().Header.Get("Content-Type")); err == nil {
-			requestContentType = t
+	if opts.validation != ResponseValidationOff && responseContentType == echo.MIMEApplicationJSON {
+		if responseSchema, err = jsonschema.ResponseBodyToSchema(outputType); err != nil {
+			return nil, fmt.Errorf("failed to get response schema: %w", err)
 		}
+	}
+
+	return echo.HandlerFunc(func(echoCtx echo.Context) error {
+		ctx := FromEchoContext(echoCtx).ctx
 
-		inputValue, err := parseInput(
-			inputFieldAttrsMap,
-			hasBody,
-			requestContentType,
-			echoCtx,
-			ctx,
-			inputType,
-		)
+		inputValue, err := bindInput(inputFieldAttrsMap, hasBody, echoCtx, ctx, inputType, opts)
 		if err != nil {
-			return ctx.Fail(NewInternalHTTPError(http.StatusBadRequest, err))
+			return err
 		}
 
 		if preHandlerHook != nil {
@@ -253,13 +426,7 @@ func createRPCHandler( //nolint:funlen,cyclop,gocognit
 		//nolint:nestif
 		if !out[1].IsNil() {
 			if err, ok := out[1].Interface().(error); ok {
-				var httpError *echo.HTTPError
-
-				if errors.As(err, &httpError) {
-					return err
-				} else {
-					return ctx.Fail(NewInternalHTTPError(http.StatusInternalServerError, err))
-				}
+				return failHandler(ctx, err)
 			} else {
 				//nolint:goerr113
 				return ctx.Fail(NewInternalHTTPError(http.StatusInternalServerError,
@@ -274,6 +441,36 @@ func createRPCHandler( //nolint:funlen,cyclop,gocognit
 			postHandlerHook(ctx, inputValue.Interface(), output)
 		}
 
+		status := routeStatus
+		if coder, ok := output.(StatusCoder); ok && coder.StatusCode() != 0 {
+			status = coder.StatusCode()
+		}
+
+		if outputAttrs.statusField != nil {
+			if statusField, err := out[0].FieldByIndexErr(outputAttrs.statusField); err == nil && statusField.Int() != 0 {
+				status = int(statusField.Int())
+			}
+		}
+
+		for name, index := range outputAttrs.headers {
+			headerField, err := out[0].FieldByIndexErr(index)
+			if err != nil {
+				continue
+			}
+
+			if err := setResponseHeader(echoCtx.Response().Header(), name, headerField); err != nil {
+				return ctx.Fail(NewInternalHTTPError(http.StatusInternalServerError, err))
+			}
+		}
+
+		if isNoContentStatus(status) {
+			if err := echoCtx.NoContent(status); err != nil {
+				return ctx.Fail(NewInternalHTTPError(http.StatusInternalServerError, fmt.Errorf("error writing response: %w", err)))
+			}
+
+			return nil
+		}
+
 		outRes, err := jsonschema.ValueToAny(reflect.ValueOf(output))
 		if err != nil {
 			return ctx.Fail(NewInternalHTTPError(http.StatusInternalServerError,
@@ -281,16 +478,28 @@ func createRPCHandler( //nolint:funlen,cyclop,gocognit
 			))
 		}
 
+		if outMap, ok := outRes.(map[string]any); ok {
+			for name := range outputAttrs.headers {
+				delete(outMap, name)
+			}
+
+			delete(outMap, outputAttrs.statusName)
+		}
+
+		if responseSchema != nil {
+			if err := checkResponse(ctx, responseSchema, opts.validation, outRes); err != nil {
+				return ctx.Fail(NewInternalHTTPError(http.StatusInternalServerError, err))
+			}
+		}
+
 		//nolint:nestif
 		if responseContentType == echo.MIMEApplicationJSON {
-			err := echoCtx.JSON(http.StatusOK, outRes)
+			err := echoCtx.JSON(status, outRes)
 			if err != nil {
 				return ctx.Fail(NewInternalHTTPError(http.StatusInternalServerError, fmt.Errorf("error writing response: %w", err)))
 			}
-		} else if responseContentType == "text/event-stream" {
-			return nil
 		} else if bytes, ok := outRes.([]byte); ok {
-			err := echoCtx.Blob(http.StatusOK, responseContentType, bytes)
+			err := echoCtx.Blob(status, responseContentType, bytes)
 			if err != nil {
 				return ctx.Fail(NewInternalHTTPError(http.StatusInternalServerError, fmt.Errorf("error writing response: %w", err)))
 			}
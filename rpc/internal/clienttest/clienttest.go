@@ -0,0 +1,18 @@
+// Package clienttest holds handlers whose types can be imported by the go clients generated in the rpc tests.
+package clienttest
+
+import "github.com/DimmyJing/valise/vctx"
+
+type WidgetInput struct {
+	ID    string `in:"path"  json:"id"`
+	Color string `in:"query" json:"color"`
+}
+
+type WidgetOutput struct {
+	ID    string `json:"id"`
+	Color string `json:"color"`
+}
+
+func HandlerWidget(inp WidgetInput, _ vctx.Context) (WidgetOutput, error) {
+	return WidgetOutput(inp), nil
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/DimmyJing/valise/attr"
+	"github.com/DimmyJing/valise/jsonschema"
 	"github.com/DimmyJing/valise/log"
 	"github.com/DimmyJing/valise/vctx"
 	"github.com/labstack/echo/v4"
@@ -55,8 +56,9 @@ type Handler func(ctx vctx.Context) error
 var errHandlerPanic = errors.New("handler panic")
 
 type ErrorMessage struct {
-	Code    string `json:"code,omitempty"`
-	Message string `json:"message,omitempty"`
+	Code    string                       `json:"code,omitempty"`
+	Message string                       `json:"message,omitempty"`
+	Errors  []jsonschema.ValidationError `json:"errors,omitempty"`
 }
 
 func NewInternalHTTPError(code int, err error) *echo.HTTPError {
@@ -66,26 +68,56 @@ func NewInternalHTTPError(code int, err error) *echo.HTTPError {
 func NewHTTPError(code int, messages ...string) *echo.HTTPError {
 	switch len(messages) {
 	case 0:
-		return echo.NewHTTPError(code, ErrorMessage{Message: "", Code: ""})
+		return echo.NewHTTPError(code, ErrorMessage{Message: "", Code: "", Errors: nil})
 	case 1:
-		return echo.NewHTTPError(code, ErrorMessage{Message: messages[0], Code: ""})
+		return echo.NewHTTPError(code, ErrorMessage{Message: messages[0], Code: "", Errors: nil})
 	default:
-		return echo.NewHTTPError(code, ErrorMessage{Message: messages[0], Code: messages[1]})
+		return echo.NewHTTPError(code, ErrorMessage{Message: messages[0], Code: messages[1], Errors: nil})
 	}
 }
 
+const ValidationErrorCode = "validation_error"
+
+func NewValidationHTTPError(errs []jsonschema.ValidationError) *echo.HTTPError {
+	return echo.NewHTTPError(http.StatusBadRequest, ErrorMessage{
+		Code:    ValidationErrorCode,
+		Message: "request validation failed",
+		Errors:  errs,
+	})
+}
+
+const DecodeErrorCode = "decode_error"
+
+func NewDecodeHTTPError(err *jsonschema.DecodeError) *echo.HTTPError {
+	return echo.NewHTTPError(http.StatusBadRequest, ErrorMessage{
+		Code:    DecodeErrorCode,
+		Message: "request decoding failed",
+		Errors:  []jsonschema.ValidationError{{Path: err.Path, Message: err.Err.Error()}},
+	}).SetInternal(err)
+}
+
 func HTTPErrorHandler(err error, echoCtx echo.Context) {
+	status, msg := resolveError(err)
+	_ = echoCtx.JSON(status, msg)
+}
+
+// resolveError converts err into the status code and public message written by the error handlers.
+func resolveError(err error) (int, ErrorMessage) {
 	var httpError *echo.HTTPError
 
 	if errors.As(err, &httpError) {
 		if msg, ok := httpError.Message.(ErrorMessage); ok {
-			_ = echoCtx.JSON(httpError.Code, msg)
-		} else {
-			_ = echoCtx.JSON(httpError.Code, ErrorMessage{Code: "", Message: ""})
+			return httpError.Code, msg
+		} else if registered, found := lookupError(err); found {
+			return registered.status, ErrorMessage{Code: registered.code, Message: registered.message, Errors: nil}
 		}
-	} else {
-		_ = echoCtx.JSON(http.StatusInternalServerError, ErrorMessage{Code: "", Message: ""})
+
+		return httpError.Code, ErrorMessage{Code: "", Message: "", Errors: nil}
+	} else if registered, found := lookupError(err); found {
+		return registered.status, ErrorMessage{Code: registered.code, Message: registered.message, Errors: nil}
 	}
+
+	return http.StatusInternalServerError, ErrorMessage{Code: "", Message: "", Errors: nil}
 }
 
 func InitMiddleware(tracer trace.Tracer, meter metric.Meter, logger otellog.Logger) echo.MiddlewareFunc {
@@ -145,7 +177,7 @@ func OTelMiddleware(skipper func(echo.Context) bool) echo.MiddlewareFunc {
 			case err != nil:
 				rootSpan.SetAttributes(semconv.HTTPResponseStatusCode(http.StatusInternalServerError))
 			default:
-				rootSpan.SetAttributes(semconv.HTTPResponseStatusCode(http.StatusOK))
+				rootSpan.SetAttributes(semconv.HTTPResponseStatusCode(echoCtx.Response().Status))
 			}
 
 			return err
@@ -250,54 +282,55 @@ func handleAuth(ctx vctx.Context, auth string, tokenVerifier TokenVerifier, deve
 	}
 }
 
-func AuthMiddleware(tokenVerifier TokenVerifier, development bool) echo.MiddlewareFunc {
-	return echo.MiddlewareFunc(func(next echo.HandlerFunc) echo.HandlerFunc {
-		return echo.HandlerFunc(func(echoCtx echo.Context) error {
-			intEchoCtx := FromEchoContext(echoCtx)
-			cctx := intEchoCtx.ctx
-			request := echoCtx.Request()
-
-			auth := request.Header.Get("Authorization")
-			if auth == "" {
-				return cctx.Fail(echo.NewHTTPError(http.StatusUnauthorized, errNoAuthHeader))
-			}
+// authMiddleware checks bearer tokens, either requiring them or letting requests without one through. Its middlewares
+// are method values, so that routes using them can be told apart and documented with BearerSecurityScheme.
+type authMiddleware struct {
+	tokenVerifier TokenVerifier
+	development   bool
+}
 
-			userID, err := handleAuth(cctx, auth, tokenVerifier, development)
-			if err != nil {
-				return err
-			}
+// AuthMiddleware requires a bearer token. Routes passing it as a Middleware option are documented with
+// BearerSecurityScheme, while routes behind it through echo.Use or group.Use need SetDefaultSecurity.
+func AuthMiddleware(tokenVerifier TokenVerifier, development bool) echo.MiddlewareFunc {
+	return authMiddleware{tokenVerifier: tokenVerifier, development: development}.require
+}
 
-			cctx.SetAttributes(attr.String(string(semconv.EnduserIDKey), userID))
+func MaybeAuthMiddleware(tokenVerifier TokenVerifier, development bool) echo.MiddlewareFunc {
+	return authMiddleware{tokenVerifier: tokenVerifier, development: development}.allow
+}
 
-			cctx = cctx.WithUserID(userID)
+func (a authMiddleware) require(next echo.HandlerFunc) echo.HandlerFunc {
+	return a.handle(next, true)
+}
 
-			return next(intEchoCtx.WithCtx(cctx))
-		})
-	})
+func (a authMiddleware) allow(next echo.HandlerFunc) echo.HandlerFunc {
+	return a.handle(next, false)
 }
 
-func MaybeAuthMiddleware(tokenVerifier TokenVerifier, development bool) echo.MiddlewareFunc {
-	return echo.MiddlewareFunc(func(next echo.HandlerFunc) echo.HandlerFunc {
-		return echo.HandlerFunc(func(echoCtx echo.Context) error {
-			intEchoCtx := FromEchoContext(echoCtx)
-			cctx := intEchoCtx.ctx
-			request := echoCtx.Request()
+func (a authMiddleware) handle(next echo.HandlerFunc, required bool) echo.HandlerFunc {
+	return echo.HandlerFunc(func(echoCtx echo.Context) error {
+		intEchoCtx := FromEchoContext(echoCtx)
+		cctx := intEchoCtx.ctx
+		request := echoCtx.Request()
 
-			auth := request.Header.Get("Authorization")
-			if auth == "" {
-				return next(intEchoCtx)
+		auth := request.Header.Get("Authorization")
+		if auth == "" {
+			if required {
+				return cctx.Fail(echo.NewHTTPError(http.StatusUnauthorized, errNoAuthHeader))
 			}
 
-			userID, err := handleAuth(cctx, auth, tokenVerifier, development)
-			if err != nil {
-				return err
-			}
+			return next(intEchoCtx)
+		}
+
+		userID, err := handleAuth(cctx, auth, a.tokenVerifier, a.development)
+		if err != nil {
+			return err
+		}
 
-			cctx.SetAttributes(attr.String(string(semconv.EnduserIDKey), userID))
+		cctx.SetAttributes(attr.String(string(semconv.EnduserIDKey), userID))
 
-			cctx = cctx.WithUserID(userID)
+		cctx = cctx.WithUserID(userID)
 
-			return next(intEchoCtx.WithCtx(cctx))
-		})
+		return next(intEchoCtx.WithCtx(cctx))
 	})
 }
@@ -9,7 +9,9 @@ import (
 	"regexp"
 	"runtime"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/DimmyJing/valise/jsonschema"
 	"github.com/DimmyJing/valise/vctx"
@@ -18,9 +20,15 @@ import (
 )
 
 type openAPIObject struct {
-	OpenAPI string                                                      `json:"openapi"`
-	Info    openAPIInfo                                                 `json:"info"`
-	Paths   *orderedmap.OrderedMap[string, map[string]openAPIOperation] `json:"paths"`
+	OpenAPI    string                                                      `json:"openapi"`
+	Info       openAPIInfo                                                 `json:"info"`
+	Paths      *orderedmap.OrderedMap[string, map[string]openAPIOperation] `json:"paths"`
+	Components *openAPIComponents                                          `json:"components,omitempty"`
+}
+
+type openAPIComponents struct {
+	Schemas         *orderedmap.OrderedMap[string, *jsonschema.JSONSchema] `json:"schemas,omitempty"`
+	SecuritySchemes *orderedmap.OrderedMap[string, SecurityScheme]         `json:"securitySchemes,omitempty"`
 }
 
 type openAPIInfo struct {
@@ -35,6 +43,18 @@ type openAPIOperation struct {
 	Responses   map[string]openAPIResponse    `json:"responses"`
 	Parameters  []jsonschema.OpenAPIParameter `json:"parameters,omitempty"`
 	RequestBody *openAPIRequestBody           `json:"requestBody,omitempty"`
+	Security    []Security                    `json:"security,omitempty"`
+	WebSocket   *openAPIWebSocket             `json:"x-websocket,omitempty"`
+
+	// inputType and outputType are set for rpc handlers, for generating go clients.
+	inputType  reflect.Type
+	outputType reflect.Type
+}
+
+// openAPIWebSocket documents the messages of a WebSocket route, which OpenAPI has no native support for.
+type openAPIWebSocket struct {
+	ClientMessage *jsonschema.JSONSchema `json:"clientMessage"`
+	ServerMessage *jsonschema.JSONSchema `json:"serverMessage"`
 }
 
 type openAPIRequestBody struct {
@@ -48,8 +68,9 @@ type openAPIMediaType struct {
 }
 
 type openAPIResponse struct {
-	Description string                      `json:"description,omitempty"`
-	Content     map[string]openAPIMediaType `json:"content"`
+	Description string                              `json:"description,omitempty"`
+	Headers     map[string]jsonschema.OpenAPIHeader `json:"headers,omitempty"`
+	Content     map[string]openAPIMediaType         `json:"content,omitempty"`
 }
 
 type OpenAPI struct {
@@ -57,6 +78,13 @@ type OpenAPI struct {
 	pathMap         *orderedmap.OrderedMap[string, openAPIOperation]
 	preHandlerHook  func(vctx.Context, any) vctx.Context
 	postHandlerHook func(vctx.Context, any, any)
+	strictDecoding  bool
+	defaultErrors   []int
+	problemDetails  bool
+	errorRefs       []string
+	validation      ResponseValidation
+	defaultSecurity []Security
+	reflector       *jsonschema.Reflector
 }
 
 func New(
@@ -84,6 +112,13 @@ func New(
 		pathMap:         orderedmap.New[string, openAPIOperation](),
 		preHandlerHook:  nil,
 		postHandlerHook: nil,
+		strictDecoding:  true,
+		defaultErrors:   nil,
+		problemDetails:  false,
+		errorRefs:       nil,
+		validation:      ResponseValidationOff,
+		defaultSecurity: nil,
+		reflector:       jsonschema.NewReflector(componentsSchemaPrefix),
 	}
 }
 
@@ -95,6 +130,29 @@ func (o *OpenAPI) RegisterPostHandlerHook(hook func(vctx.Context, any, any)) {
 	o.postHandlerHook = hook
 }
 
+// SetDefaultErrors sets the error status codes documented on every route registered afterwards.
+func (o *OpenAPI) SetDefaultErrors(codes ...int) {
+	o.defaultErrors = codes
+}
+
+// SetStrictDecoding sets whether routes registered afterwards reject unknown keys in the request body.
+// Strict decoding is enabled by default, matching the additionalProperties: false of the generated schemas.
+func (o *OpenAPI) SetStrictDecoding(strict bool) {
+	o.strictDecoding = strict
+}
+
+// SetProblemDetails sets whether routes registered afterwards document their errors as application/problem+json,
+// as written by ProblemHTTPErrorHandler.
+func (o *OpenAPI) SetProblemDetails(enabled bool) {
+	o.problemDetails = enabled
+}
+
+// SetResponseValidation sets whether routes registered afterwards check their responses against their schema, which
+// catches handlers that drift from their documentation during development.
+func (o *OpenAPI) SetResponseValidation(mode ResponseValidation) {
+	o.validation = mode
+}
+
 type EchoInterface interface {
 	Add(method, path string, handler echo.HandlerFunc, middleware ...echo.MiddlewareFunc) *echo.Route
 }
@@ -144,6 +202,21 @@ func (o *OpenAPI) PATCH(
 	return o.Add(ech, http.MethodPatch, path, handler, options...)
 }
 
+type routeOptions struct {
+	description         string
+	tags                []string
+	requestContentType  string
+	responseContentType string
+	strictDecoding      bool
+	statuses            []int
+	errors              []int
+	problemDetails      bool
+	heartbeat           time.Duration
+	origins             []string
+	validation          ResponseValidation
+	security            []Security
+}
+
 func (o *OpenAPI) Add(
 	ech EchoInterface,
 	method string,
@@ -151,36 +224,67 @@ func (o *OpenAPI) Add(
 	handler any,
 	options ...PathOption,
 ) (echo.HandlerFunc, error) {
-	description := ""
 	middlewares := []echo.MiddlewareFunc{}
-	tags := []string{}
-	requestContentType := echo.MIMEApplicationForm
-	responseContentType := echo.MIMEApplicationJSON
+	opts := routeOptions{
+		description:         "",
+		tags:                []string{},
+		requestContentType:  echo.MIMEApplicationForm,
+		responseContentType: echo.MIMEApplicationJSON,
+		strictDecoding:      o.strictDecoding,
+		statuses:            nil,
+		errors:              slices.Clone(o.defaultErrors),
+		problemDetails:      o.problemDetails,
+		heartbeat:           0,
+		origins:             nil,
+		validation:          o.validation,
+		security:            o.defaultSecurity,
+	}
+
+	var security []Security
 
 	for _, option := range options {
 		switch opt := option.(type) {
 		case Middleware:
 			middlewares = append(middlewares, echo.MiddlewareFunc(opt))
+
+			if auth, found := authSecurity(echo.MiddlewareFunc(opt)); found {
+				security = append(security, auth...)
+			}
+		case withAuth:
+			middlewares = append(middlewares, opt.middleware)
+			security = append(security, opt.security...)
+		case Security:
+			security = append(security, opt)
 		case withDescription:
-			description = opt.description
+			opts.description = opt.description
 		case withTags:
-			tags = append(tags, opt.tags...)
+			opts.tags = append(opts.tags, opt.tags...)
 		case withRequestContentType:
-			requestContentType = opt.contentType
+			opts.requestContentType = opt.contentType
 		case withResponseContentType:
-			responseContentType = opt.contentType
+			opts.responseContentType = opt.contentType
+		case withStrictDecoding:
+			opts.strictDecoding = opt.strict
+		case withStatus:
+			opts.statuses = append(opts.statuses, opt.codes...)
+		case withHeartbeat:
+			opts.heartbeat = opt.interval
+		case withOrigins:
+			opts.origins = append(opts.origins, opt.origins...)
+		case withErrors:
+			for _, code := range opt.codes {
+				if !slices.Contains(opts.errors, code) {
+					opts.errors = append(opts.errors, code)
+				}
+			}
 		}
 	}
 
-	newHandler, handlerName, err := o.createHandler(
-		handler,
-		path,
-		method,
-		description,
-		tags,
-		requestContentType,
-		responseContentType,
-	)
+	if security != nil {
+		opts.security = security
+	}
+
+	newHandler, handlerName, err := o.createHandler(handler, path, method, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create handler: %w", err)
 	}
@@ -228,7 +332,20 @@ func (o *OpenAPI) Flush(ech *echo.Echo) error {
 		}
 	}
 
-	return nil
+	for pair := o.reflector.Definitions().Oldest(); pair != nil; pair = pair.Next() {
+		o.setComponentSchema(pair.Key, pair.Value)
+	}
+
+	// the error handlers write an error body on every route, whether or not it documents its errors.
+	if len(o.errorRefs) == 0 {
+		if err := o.registerErrorSchema(o.problemDetails); err != nil {
+			return err
+		}
+	}
+
+	o.setComponentSchema(errorCodeName, errorCodeSchema())
+
+	return o.checkSecurity()
 }
 
 func (o *OpenAPI) Document() ([]byte, error) {
@@ -242,43 +359,133 @@ func (o *OpenAPI) Document() ([]byte, error) {
 
 var errInvalidHandler = errors.New("invalid handler")
 
+const (
+	componentsSchemaPrefix = "#/components/schemas/"
+	errorMessageRef        = componentsSchemaPrefix + "ErrorMessage"
+	problemDetailsRef      = componentsSchemaPrefix + "ProblemDetails"
+	errorCodeName          = "ErrorCode"
+)
+
+func (o *OpenAPI) components() *openAPIComponents {
+	if o.document.Components == nil {
+		o.document.Components = &openAPIComponents{Schemas: nil, SecuritySchemes: nil}
+	}
+
+	return o.document.Components
+}
+
+func (o *OpenAPI) setComponentSchema(name string, schema *jsonschema.JSONSchema) {
+	components := o.components()
+	if components.Schemas == nil {
+		components.Schemas = orderedmap.New[string, *jsonschema.JSONSchema]()
+	}
+
+	components.Schemas.Set(name, schema)
+}
+
+func (o *OpenAPI) registerErrorSchema(problemDetails bool) error {
+	ref, errorType := errorMessageRef, reflect.TypeOf(ErrorMessage{}) //nolint:exhaustruct
+	if problemDetails {
+		ref, errorType = problemDetailsRef, reflect.TypeOf(ProblemDetails{}) //nolint:exhaustruct
+	}
+
+	// the error body is recorded per route, as SetProblemDetails may change between the routes.
+	if slices.Contains(o.errorRefs, ref) {
+		return nil
+	}
+
+	o.errorRefs = append(o.errorRefs, ref)
+
+	schema, err := jsonschema.AnyToSchema(errorType)
+	if err != nil {
+		return fmt.Errorf("failed to convert error message to schema: %w", err)
+	}
+
+	o.setComponentSchema(jsonschema.RefName(ref), schema)
+
+	return nil
+}
+
 func (o *OpenAPI) createHandler(
 	handler any,
 	path string,
 	method string,
-	description string,
-	tags []string,
-	requestContentType string,
-	responseContentType string,
+	opts routeOptions,
 ) (echo.HandlerFunc, string, error) {
-	if inputType, outputType, ok := isRPCHandler(handler); ok {
-		handlerName := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
-		handlerName = fmt.Sprintf("%s.%s.%s", path, method, handlerName)
+	var (
+		handlerFn  echo.HandlerFunc
+		inputType  reflect.Type
+		outputType reflect.Type
+		isRPC      bool
+		err        error
+	)
+
+	if rpcInput, rpcOutput, ok := isRPCHandler(handler); ok {
+		inputType, outputType, isRPC = rpcInput, rpcOutput, true
 
-		handlerFn, err := createRPCHandler(
+		handlerFn, err = createRPCHandler(
 			handler,
 			method,
 			inputType,
-			requestContentType,
-			responseContentType,
+			outputType,
+			opts,
 			o.preHandlerHook,
 			o.postHandlerHook,
 		)
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to create rpc handler: %w", err)
 		}
+	} else if wsInput, connType, ok := isWSHandler(handler); ok {
+		if method != http.MethodGet {
+			return nil, "", fmt.Errorf("websocket handler must use GET, got %s: %w", method, errInvalidHandler)
+		}
+
+		handlerFn, err = createWSHandler(handler, wsInput, connType, opts, o.preHandlerHook)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create websocket handler: %w", err)
+		}
 
-		item, err := getPathItem(inputType, outputType, method, description, tags, requestContentType, responseContentType)
+		item, err := o.getWSPathItem(wsInput, connType, opts)
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to generate path item: %w", err)
 		}
 
+		handlerName := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+		handlerName = fmt.Sprintf("%s.%s.%s", path, method, handlerName)
 		o.pathMap.Set(handlerName, *item)
 
 		return handlerFn, handlerName, nil
+	} else if streamInput, eventType, streamType, ok := isStreamHandler(handler); ok {
+		inputType, outputType = streamInput, eventType
+		opts.responseContentType = MIMETextEventStream
+
+		handlerFn, err = createStreamHandler(handler, method, inputType, streamType, opts, o.preHandlerHook)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create stream handler: %w", err)
+		}
 	} else {
 		return nil, "", fmt.Errorf("handler is not a valid handler: %w", errInvalidHandler)
 	}
+
+	handlerName := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	handlerName = fmt.Sprintf("%s.%s.%s", path, method, handlerName)
+
+	item, err := o.getPathItem(inputType, outputType, method, opts)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate path item: %w", err)
+	}
+
+	if isRPC {
+		item.inputType, item.outputType = inputType, outputType
+	}
+
+	if err := o.registerErrorSchema(opts.problemDetails); err != nil {
+		return nil, "", err
+	}
+
+	o.pathMap.Set(handlerName, *item)
+
+	return handlerFn, handlerName, nil
 }
 
 //nolint:gochecknoglobals
@@ -288,47 +495,88 @@ var hasBodyMethods = []string{
 	http.MethodPatch,
 }
 
-func getPathItem(
+func (o *OpenAPI) getPathItem( //nolint:funlen
 	input reflect.Type,
 	output reflect.Type,
 	method string,
-	description string,
-	tags []string,
-	requestContentType string,
-	responseContentType string,
+	opts routeOptions,
 ) (*openAPIOperation, error) {
-	outSchema, err := jsonschema.AnyToSchema(output)
+	outSchema, err := o.reflector.ResponseBodyToSchema(output)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert output to schema: %w", err)
 	}
 
-	operation := openAPIOperation{
-		Tags:        tags,
-		Description: description,
-		Responses: map[string]openAPIResponse{"200": {
+	outHeaders, err := o.reflector.ResponseHeadersToSchema(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert output headers to schema: %w", err)
+	}
+
+	statuses := opts.statuses
+	if len(statuses) == 0 {
+		statuses = []int{defaultStatus(output, opts)}
+	}
+
+	responses := make(map[string]openAPIResponse, len(statuses))
+
+	for _, status := range statuses {
+		response := openAPIResponse{
 			Description: outSchema.Description,
-			Content:     map[string]openAPIMediaType{responseContentType: {Schema: *outSchema}},
-		}},
+			Content:     map[string]openAPIMediaType{opts.responseContentType: {Schema: *outSchema}},
+			Headers:     outHeaders,
+		}
+
+		if isNoContentStatus(status) {
+			response.Description = http.StatusText(status)
+			response.Content = nil
+		}
+
+		responses[strconv.Itoa(status)] = response
+	}
+
+	errorContentType, errorRef := echo.MIMEApplicationJSON, errorMessageRef
+	if opts.problemDetails {
+		errorContentType, errorRef = MIMEApplicationProblemJSON, problemDetailsRef
+	}
+
+	for _, code := range opts.errors {
+		responses[strconv.Itoa(code)] = openAPIResponse{
+			Description: http.StatusText(code),
+			Headers:     nil,
+			Content: map[string]openAPIMediaType{errorContentType: {
+				//nolint:exhaustruct
+				Schema: jsonschema.JSONSchema{Ref: errorRef},
+			}},
+		}
+	}
+
+	operation := openAPIOperation{
+		Tags:        opts.tags,
+		Description: opts.description,
+		Responses:   responses,
 		Parameters:  nil,
 		RequestBody: nil,
+		Security:    opts.security,
+		WebSocket:   nil,
+		inputType:   nil,
+		outputType:  nil,
 	}
 
 	hasBody := slices.Contains(hasBodyMethods, method)
 
-	operation.Parameters, err = jsonschema.ParametersToSchema(input, !hasBody)
+	operation.Parameters, err = o.reflector.ParametersToSchema(input, !hasBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert input to schema: %w", err)
 	}
 
 	if hasBody {
-		inputSchema, err := jsonschema.RequestBodyToSchema(input)
+		inputSchema, err := o.reflector.RequestBodyToSchema(input)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate schema for input: %w", err)
 		}
 
 		operation.RequestBody = &openAPIRequestBody{
 			Description: inputSchema.Description,
-			Content:     map[string]openAPIMediaType{requestContentType: {Schema: *inputSchema}},
+			Content:     map[string]openAPIMediaType{opts.requestContentType: {Schema: *inputSchema}},
 			Required:    true,
 		}
 	}
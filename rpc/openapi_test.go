@@ -2,10 +2,17 @@ package rpc_test
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/DimmyJing/valise/jsonschema"
 	"github.com/DimmyJing/valise/rpc"
 	"github.com/DimmyJing/valise/vctx"
 	"github.com/labstack/echo/v4"
@@ -51,3 +58,473 @@ func TestBasicRoute(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rec.Code)
 	assert.Equal(t, "{\"name\":\"jimmy\"}\n", rec.Body.String())
 }
+
+type testValidateInput struct {
+	Name  string `json:"name"  validate:"min=3"`
+	Email string `json:"email" validate:"format=email"`
+}
+
+func HandlerValidate(inp testValidateInput, ctx vctx.Context) (testOutput1, error) {
+	return testOutput1{Name: inp.Name}, nil
+}
+
+func TestValidation(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	handler, err := oapi.Add(ech, http.MethodPost, "/validate", HandlerValidate,
+		rpc.WithRequestContentType(echo.MIMEApplicationJSON),
+	)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(`{"name":"ab","email":"ab"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	echoCtx := ech.NewContext(req, rec)
+
+	err = handler(echoCtx)
+	require.Error(t, err)
+
+	rpc.HTTPErrorHandler(err, echoCtx)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{
+		"code": "validation_error",
+		"message": "request validation failed",
+		"errors": [
+			{"path": "/name", "message": "length must be at least 3"},
+			{"path": "/email", "message": "must be a valid email"}
+		]
+	}`, rec.Body.String())
+
+	require.NoError(t, oapi.Flush(ech))
+
+	doc, err := oapi.Document()
+	require.NoError(t, err)
+	assert.Contains(t, string(doc), `"minLength": 3`)
+}
+
+type testDecodeItem struct {
+	Price float64 `json:"price"`
+}
+
+type testDecodeInput struct {
+	Items []testDecodeItem `json:"items"`
+}
+
+func HandlerDecode(inp testDecodeInput, ctx vctx.Context) (testOutput1, error) {
+	return testOutput1{Name: ""}, nil
+}
+
+func TestDecodeError(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	strictHandler, err := oapi.Add(ech, http.MethodPost, "/strict", HandlerDecode,
+		rpc.WithRequestContentType(echo.MIMEApplicationJSON),
+	)
+	require.NoError(t, err)
+
+	lenientHandler, err := oapi.Add(ech, http.MethodPost, "/lenient", HandlerDecode,
+		rpc.WithRequestContentType(echo.MIMEApplicationJSON),
+		rpc.WithStrictDecoding(false),
+	)
+	require.NoError(t, err)
+
+	run := func(handler echo.HandlerFunc, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		echoCtx := ech.NewContext(req, rec)
+
+		if err := handler(echoCtx); err != nil {
+			rpc.HTTPErrorHandler(err, echoCtx)
+		}
+
+		return rec
+	}
+
+	rec := run(strictHandler, `{"items":[{"price":1},{"price":"abc"}]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"decode_error"`)
+	assert.Contains(t, rec.Body.String(), `"path":"/items/1/price"`)
+
+	rec = run(strictHandler, `{"items":[],"extra":true}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"path":"/extra"`)
+
+	rec = run(lenientHandler, `{"items":[],"extra":true}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+type testHeaderInput struct {
+	ID             string   `in:"path"   json:"id"`
+	IdempotencyKey string   `in:"header" json:"X-Idempotency-Key"`
+	IfMatch        []string `in:"header" json:"If-Match,omitempty"`
+	Session        string   `in:"cookie" json:"session"`
+}
+
+type testHeaderOutput struct {
+	ID             string   `json:"id"`
+	IdempotencyKey string   `json:"idempotencyKey"`
+	IfMatch        []string `json:"ifMatch"`
+	Session        string   `json:"session"`
+}
+
+func HandlerHeader(inp testHeaderInput, ctx vctx.Context) (testHeaderOutput, error) {
+	return testHeaderOutput(inp), nil
+}
+
+func TestHeaderCookieParams(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	ech.HTTPErrorHandler = rpc.HTTPErrorHandler
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	_, err := oapi.PUT(ech, "/items/:id", HandlerHeader, rpc.WithRequestContentType(echo.MIMEApplicationJSON))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPut, "/items/abc", strings.NewReader(`{}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("X-Idempotency-Key", "key")
+	req.Header.Add("If-Match", "a")
+	req.Header.Add("If-Match", "b")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "sess"}) //nolint:exhaustruct
+	rec := httptest.NewRecorder()
+	ech.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id":"abc","idempotencyKey":"key","ifMatch":["a","b"],"session":"sess"}`, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodPut, "/items/abc", strings.NewReader(`{}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	ech.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"path":"/X-Idempotency-Key"`)
+
+	require.NoError(t, oapi.Flush(ech))
+
+	doc, err := oapi.Document()
+	require.NoError(t, err)
+	assert.Contains(t, string(doc), `"in": "header"`)
+	assert.Contains(t, string(doc), `"in": "cookie"`)
+}
+
+type testCreatedOutput struct {
+	ID       string `json:"id"`
+	Location string `json:"Location" out:"header"`
+}
+
+func HandlerCreated(inp testInput1, ctx vctx.Context) (testCreatedOutput, error) {
+	return testCreatedOutput{ID: inp.Name, Location: "/items/" + inp.Name}, nil
+}
+
+type testAcceptedOutput struct {
+	Status int    `json:"-" out:"status"`
+	Job    string `json:"job"`
+}
+
+func HandlerAccepted(inp testInput1, ctx vctx.Context) (testAcceptedOutput, error) {
+	if inp.Name == "now" {
+		return testAcceptedOutput{Status: http.StatusNoContent, Job: ""}, nil
+	}
+
+	return testAcceptedOutput{Status: 0, Job: inp.Name}, nil
+}
+
+type testDeletedOutput struct{}
+
+func (testDeletedOutput) StatusCode() int {
+	return http.StatusNoContent
+}
+
+func HandlerDeleted(inp testInput1, ctx vctx.Context) (testDeletedOutput, error) {
+	return testDeletedOutput{}, nil
+}
+
+func TestResponseStatus(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	ech := echo.New()
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	_, err := oapi.POST(ech, "/created", HandlerCreated,
+		rpc.WithRequestContentType(echo.MIMEApplicationJSON), rpc.WithStatus(http.StatusCreated))
+	require.NoError(t, err)
+
+	_, err = oapi.POST(ech, "/accepted", HandlerAccepted,
+		rpc.WithRequestContentType(echo.MIMEApplicationJSON), rpc.WithStatus(http.StatusAccepted, http.StatusNoContent))
+	require.NoError(t, err)
+
+	_, err = oapi.DELETE(ech, "/deleted", HandlerDeleted)
+	require.NoError(t, err)
+
+	run := func(method string, path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ech.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := run(http.MethodPost, "/created", `{"name":"a"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "/items/a", rec.Header().Get("Location"))
+	assert.JSONEq(t, `{"id":"a"}`, rec.Body.String())
+
+	rec = run(http.MethodPost, "/accepted", `{"name":"later"}`)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.JSONEq(t, `{"job":"later"}`, rec.Body.String())
+
+	rec = run(http.MethodPost, "/accepted", `{"name":"now"}`)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	rec = run(http.MethodDelete, "/deleted?name=a", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	require.NoError(t, oapi.Flush(ech))
+
+	doc, err := oapi.Document()
+	require.NoError(t, err)
+
+	var parsed struct {
+		Paths map[string]map[string]struct {
+			Responses map[string]struct {
+				Headers map[string]any `json:"headers"`
+				Content map[string]any `json:"content"`
+			} `json:"responses"`
+		} `json:"paths"`
+	}
+
+	require.NoError(t, json.Unmarshal(doc, &parsed))
+
+	created := parsed.Paths["/created"]["post"].Responses
+	assert.Contains(t, created, "201")
+	assert.NotContains(t, created, "200")
+	assert.Contains(t, created["201"].Headers, "Location")
+	assert.NotContains(t, string(doc), `"title": "Location"`)
+
+	accepted := parsed.Paths["/accepted"]["post"].Responses
+	assert.Contains(t, accepted, "202")
+	assert.Contains(t, accepted, "204")
+	assert.Nil(t, accepted["204"].Content)
+
+	assert.Contains(t, parsed.Paths["/deleted"]["delete"].Responses, "204")
+}
+
+type testPagination struct {
+	Page  int `in:"query" json:"page"`
+	Limit int `in:"query" json:"limit,omitempty"`
+}
+
+type testAudit struct {
+	RequestID string `json:"X-Request-Id" out:"header"`
+	CreatedBy string `json:"createdBy"`
+}
+
+type testListInput struct {
+	testPagination
+	Name string `in:"path" json:"name"`
+}
+
+type testListOutput struct {
+	*testAudit
+	Items []string `json:"items"`
+}
+
+func HandlerList(inp testListInput, ctx vctx.Context) (testListOutput, error) {
+	return testListOutput{
+		testAudit: &testAudit{RequestID: "req", CreatedBy: inp.Name},
+		Items:     []string{strconv.Itoa(inp.Page), strconv.Itoa(inp.Limit)},
+	}, nil
+}
+
+func TestEmbeddedFields(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	ech.HTTPErrorHandler = rpc.HTTPErrorHandler
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	_, err := oapi.GET(ech, "/lists/:name", HandlerList)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/lists/a?page=2&limit=10", nil)
+	rec := httptest.NewRecorder()
+	ech.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "req", rec.Header().Get("X-Request-Id"))
+	assert.JSONEq(t, `{"createdBy":"a","items":["2","10"]}`, rec.Body.String())
+
+	require.NoError(t, oapi.Flush(ech))
+
+	doc, err := oapi.Document()
+	require.NoError(t, err)
+	assert.Contains(t, string(doc), `"name": "page"`)
+	assert.Contains(t, string(doc), `"X-Request-Id": {`)
+	assert.NotContains(t, string(doc), "testPagination")
+	assert.NotContains(t, string(doc), "testAudit")
+}
+
+type testPatchInput struct {
+	ID   string                      `in:"path" json:"id"`
+	Name jsonschema.Optional[string] `json:"name"`
+}
+
+func HandlerPatch(inp testPatchInput, ctx vctx.Context) (testOutput1, error) {
+	switch {
+	case !inp.Name.Set:
+		return testOutput1{Name: "absent"}, nil
+	case inp.Name.Null:
+		return testOutput1{Name: "null"}, nil
+	default:
+		return testOutput1{Name: inp.Name.Value}, nil
+	}
+}
+
+func TestOptionalPatch(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	ech.HTTPErrorHandler = rpc.HTTPErrorHandler
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	_, err := oapi.PATCH(ech, "/items/:id", HandlerPatch, rpc.WithRequestContentType(echo.MIMEApplicationJSON))
+	require.NoError(t, err)
+
+	for body, expected := range map[string]string{`{}`: "absent", `{"name":null}`: "null", `{"name":"a"}`: "a"} {
+		req := httptest.NewRequest(http.MethodPatch, "/items/1", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ech.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"name":"`+expected+`"}`, rec.Body.String())
+	}
+}
+
+type testID [4]byte
+
+func (id testID) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(id[:])), nil
+}
+
+func (id *testID) UnmarshalText(text []byte) error {
+	_, err := hex.Decode(id[:], text)
+
+	//nolint:wrapcheck
+	return err
+}
+
+type testTextInput struct {
+	ID      testID        `in:"path"  json:"id"`
+	Client  net.IP        `in:"query" json:"client"`
+	Hosts   []net.IP      `in:"query" json:"hosts"`
+	Timeout time.Duration `in:"query" json:"timeout"`
+}
+
+type testTextOutput struct {
+	ID      testID        `json:"id"`
+	Client  net.IP        `json:"client"`
+	Hosts   []net.IP      `json:"hosts"`
+	Timeout time.Duration `json:"timeout"`
+}
+
+func HandlerText(inp testTextInput, ctx vctx.Context) (testTextOutput, error) {
+	return testTextOutput(inp), nil
+}
+
+func TestTextParams(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	ech.HTTPErrorHandler = rpc.HTTPErrorHandler
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	_, err := oapi.GET(ech, "/hosts/:id", HandlerText)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet,
+		"/hosts/0a0b0c0d?client=10.0.0.1&hosts=::1&hosts=10.0.0.2&timeout=1m30s", nil)
+	rec := httptest.NewRecorder()
+	ech.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id":"0a0b0c0d","client":"10.0.0.1","hosts":["::1","10.0.0.2"],"timeout":90000000000}`,
+		rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/hosts/xyz?client=10.0.0.1&hosts=::1&timeout=1s", nil)
+	rec = httptest.NewRecorder()
+	ech.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"path":"/id"`)
+
+	req = httptest.NewRequest(http.MethodGet, "/hosts/0a0b0c0d?client=10.0.0.1&hosts=::1&timeout=90000000000", nil)
+	rec = httptest.NewRecorder()
+	ech.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"timeout":90000000000`)
+
+	req = httptest.NewRequest(http.MethodGet, "/hosts/0a0b0c0d?client=10.0.0.1&hosts=::1&timeout=soon", nil)
+	rec = httptest.NewRecorder()
+	ech.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"path":"/timeout"`)
+}
+
+type testState string
+
+func (testState) Members() []string {
+	return []string{"open", "closed"}
+}
+
+type testStateInput struct {
+	State string `json:"state"`
+}
+
+type testStateOutput struct {
+	State testState `json:"state"`
+	Owner *string   `json:"owner,optional"`
+}
+
+func HandlerState(inp testStateInput, ctx vctx.Context) (testStateOutput, error) {
+	return testStateOutput{State: testState(inp.State), Owner: nil}, nil
+}
+
+func TestResponseValidation(t *testing.T) {
+	t.Parallel()
+
+	for mode, invalidStatus := range map[rpc.ResponseValidation]int{
+		rpc.ResponseValidationOff:  http.StatusOK,
+		rpc.ResponseValidationLog:  http.StatusOK,
+		rpc.ResponseValidationFail: http.StatusInternalServerError,
+	} {
+		ech := echo.New()
+		ech.HTTPErrorHandler = rpc.HTTPErrorHandler
+		oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+		oapi.SetResponseValidation(mode)
+
+		_, err := oapi.GET(ech, "/state", HandlerState)
+		require.NoError(t, err)
+
+		for query, expected := range map[string]int{"open": http.StatusOK, "stuck": invalidStatus} {
+			req := httptest.NewRequest(http.MethodGet, "/state?state="+query, nil)
+			rec := httptest.NewRecorder()
+			ech.ServeHTTP(rec, req)
+
+			assert.Equal(t, expected, rec.Code, "mode %d state %s", mode, query)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package rpc
 
-import "github.com/labstack/echo/v4"
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
 
 type PathOption interface {
 	privatePathOption()
@@ -49,3 +53,60 @@ func (w withResponseContentType) privatePathOption() {}
 func WithResponseContentType(contentType string) withResponseContentType {
 	return withResponseContentType{contentType: contentType}
 }
+
+type withStrictDecoding struct {
+	strict bool
+}
+
+func (w withStrictDecoding) privatePathOption() {}
+
+// WithStrictDecoding overrides whether unknown keys in the request body are rejected for this route.
+func WithStrictDecoding(strict bool) withStrictDecoding {
+	return withStrictDecoding{strict: strict}
+}
+
+type withStatus struct {
+	codes []int
+}
+
+func (w withStatus) privatePathOption() {}
+
+// WithStatus documents the success status codes of a route. The first code is written unless the
+// output sets its own status through an out:"status" field or by implementing StatusCoder.
+func WithStatus(codes ...int) withStatus {
+	return withStatus{codes: codes}
+}
+
+type withErrors struct {
+	codes []int
+}
+
+func (w withErrors) privatePathOption() {}
+
+// WithErrors documents error responses of a route, in addition to the defaults set on OpenAPI.
+func WithErrors(codes ...int) withErrors {
+	return withErrors{codes: codes}
+}
+
+type withHeartbeat struct {
+	interval time.Duration
+}
+
+func (w withHeartbeat) privatePathOption() {}
+
+// WithHeartbeat makes a stream handler send a comment every interval to keep idle connections open.
+func WithHeartbeat(interval time.Duration) withHeartbeat {
+	return withHeartbeat{interval: interval}
+}
+
+type withOrigins struct {
+	origins []string
+}
+
+func (w withOrigins) privatePathOption() {}
+
+// WithOrigins allows WebSocket handshakes from pages of other origins, such as https://app.example.com, in addition
+// to those of the same origin and clients that send no Origin.
+func WithOrigins(origins ...string) withOrigins {
+	return withOrigins{origins: origins}
+}
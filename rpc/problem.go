@@ -0,0 +1,47 @@
+package rpc
+
+import (
+	"net/http"
+
+	"github.com/DimmyJing/valise/jsonschema"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// ProblemDetails is an RFC 9457 problem details object extended with the trace ID, error code and validation errors.
+type ProblemDetails struct {
+	Type     string                       `json:"type"`
+	Title    string                       `json:"title"`
+	Status   int                          `json:"status"`
+	Detail   string                       `json:"detail,omitempty"`
+	Instance string                       `json:"instance,omitempty"`
+	TraceID  string                       `json:"traceId,omitempty"`
+	Code     string                       `json:"code,omitempty"`
+	Errors   []jsonschema.ValidationError `json:"errors,omitempty"`
+}
+
+// ProblemHTTPErrorHandler writes errors as application/problem+json instead of the ErrorMessage shape used by
+// HTTPErrorHandler. Use it together with OpenAPI.SetProblemDetails so the document matches the responses.
+func ProblemHTTPErrorHandler(err error, echoCtx echo.Context) {
+	status, msg := resolveError(err)
+
+	problem := ProblemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   msg.Message,
+		Instance: echoCtx.Request().URL.Path,
+		TraceID:  "",
+		Code:     msg.Code,
+		Errors:   msg.Errors,
+	}
+
+	if spanCtx := trace.SpanContextFromContext(echoCtx.Request().Context()); spanCtx.HasTraceID() {
+		problem.TraceID = spanCtx.TraceID().String()
+	}
+
+	echoCtx.Response().Header().Set(echo.HeaderContentType, MIMEApplicationProblemJSON)
+	_ = echoCtx.JSON(status, problem)
+}
@@ -0,0 +1,88 @@
+package rpc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DimmyJing/valise/rpc"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemDetails(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	ech.HTTPErrorHandler = rpc.ProblemHTTPErrorHandler
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+	oapi.SetProblemDetails(true)
+
+	_, err := oapi.POST(ech, "/validate", HandlerValidate,
+		rpc.WithRequestContentType(echo.MIMEApplicationJSON), rpc.WithErrors(http.StatusBadRequest))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(`{"name":"ab","email":"a@b.c"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ech.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, rpc.MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Bad Request",
+		"status": 400,
+		"detail": "request validation failed",
+		"instance": "/validate",
+		"code": "validation_error",
+		"errors": [{"path": "/name", "message": "length must be at least 3"}]
+	}`, rec.Body.String())
+
+	require.NoError(t, oapi.Flush(ech))
+
+	dir := t.TempDir()
+	require.NoError(t, oapi.CodeGen(dir))
+
+	doc := readGenerated(t, dir, "swagger.json")
+	assert.Contains(t, doc, `"application/problem+json": {`)
+	assert.Contains(t, doc, `"$ref": "#/components/schemas/ProblemDetails"`)
+	assert.NotContains(t, doc, `"ErrorMessage"`)
+
+	stub := readGenerated(t, dir, "validate.ts")
+	assert.Contains(t, stub, "| { status: 400, body: ProblemDetails }")
+}
+
+func TestProblemDetailsPerRoute(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+	oapi.SetProblemDetails(true)
+
+	_, err := oapi.GET(ech, "/test", HandlerTest1)
+	require.NoError(t, err)
+
+	// the routes keep the setting they were registered with.
+	oapi.SetProblemDetails(false)
+	require.NoError(t, oapi.Flush(ech))
+
+	dir := t.TempDir()
+	require.NoError(t, oapi.CodeGen(dir))
+
+	assert.NotContains(t, readGenerated(t, dir, "swagger.json"), `"ErrorMessage"`)
+
+	client := readGenerated(t, dir, "client.ts")
+	assert.Contains(t, client, `import type { ProblemDetails } from "./common"`)
+	assert.Contains(t, client, "type ErrorType = ProblemDetails\n")
+
+	_, err = oapi.GET(ech, "/other", HandlerTest1)
+	require.NoError(t, err)
+	require.NoError(t, oapi.CodeGen(dir))
+
+	client = readGenerated(t, dir, "client.ts")
+	assert.Contains(t, client, `import type { ProblemDetails, ErrorMessage } from "./common"`)
+	assert.Contains(t, client, "type ErrorType = ProblemDetails | ErrorMessage\n")
+}
@@ -0,0 +1,89 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DimmyJing/valise/attr"
+	"github.com/DimmyJing/valise/jsonschema"
+	"github.com/DimmyJing/valise/vctx"
+)
+
+// ResponseValidation sets what happens to rpc responses that do not match the documented schema of their route.
+type ResponseValidation int
+
+const (
+	// ResponseValidationOff skips checking responses, which is the default as it costs a second pass over the output.
+	ResponseValidationOff ResponseValidation = iota
+	// ResponseValidationLog logs the mismatches and writes the response anyway.
+	ResponseValidationLog
+	// ResponseValidationFail logs the mismatches and fails the request with a 500.
+	ResponseValidationFail
+)
+
+var errResponseMismatch = errors.New("response does not match its schema")
+
+// checkResponse validates the json output of a route against schema, logging every mismatch along with the value
+// that was found at its path.
+func checkResponse(
+	ctx vctx.Context,
+	schema *jsonschema.JSONSchema,
+	mode ResponseValidation,
+	output any,
+) error {
+	validationErrors := schema.Validate(output)
+	if len(validationErrors) == 0 {
+		return nil
+	}
+
+	mismatches := make([]string, len(validationErrors))
+	for idx, validationError := range validationErrors {
+		mismatches[idx] = fmt.Sprintf("%s (got %s)", validationError.Error(), pointerValue(output, validationError.Path))
+	}
+
+	ctx.Warn(errResponseMismatch.Error(), attr.Any("mismatches", mismatches))
+
+	if mode == ResponseValidationFail {
+		return fmt.Errorf("%s: %w", strings.Join(mismatches, "; "), errResponseMismatch)
+	}
+
+	return nil
+}
+
+// pointerValue formats the json found at the json pointer path of value, which is the output of ValueToAny.
+func pointerValue(value any, path string) string {
+	if path != "" {
+		for _, token := range strings.Split(path[1:], "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+			switch container := value.(type) {
+			case map[string]any:
+				elem, found := container[token]
+				if !found {
+					return "nothing"
+				}
+
+				value = elem
+			case []any:
+				idx, err := strconv.Atoi(token)
+				if err != nil || idx < 0 || idx >= len(container) {
+					return "nothing"
+				}
+
+				value = container[idx]
+			default:
+				return "nothing"
+			}
+		}
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(encoded)
+}
@@ -0,0 +1,182 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// BearerSecurityScheme is the name of the security scheme that documents the routes using AuthMiddleware or
+// MaybeAuthMiddleware, directly or through WithAuth and WithMaybeAuth. It is added as a bearer scheme unless a scheme
+// of that name is added.
+const BearerSecurityScheme = "bearerAuth"
+
+// SecurityScheme is an OpenAPI security scheme, as built by BearerAuth, BasicAuth, APIKeyAuth and OAuth2Auth.
+type SecurityScheme struct {
+	Type             string      `json:"type"`
+	Description      string      `json:"description,omitempty"`
+	Scheme           string      `json:"scheme,omitempty"`
+	BearerFormat     string      `json:"bearerFormat,omitempty"`
+	Name             string      `json:"name,omitempty"`
+	In               string      `json:"in,omitempty"`
+	Flows            *OAuthFlows `json:"flows,omitempty"`
+	OpenIDConnectURL string      `json:"openIdConnectUrl,omitempty"`
+}
+
+type OAuthFlows struct {
+	Implicit          *OAuthFlow `json:"implicit,omitempty"`
+	Password          *OAuthFlow `json:"password,omitempty"`
+	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+	AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+}
+
+// OAuthFlow configures an OAuth2 flow, with the scopes it grants mapped to their description.
+type OAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	RefreshURL       string            `json:"refreshUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes"`
+}
+
+// BearerAuth is a scheme for an Authorization: Bearer header, where format hints at the kind of token, e.g. "JWT".
+func BearerAuth(format string) SecurityScheme {
+	//nolint:exhaustruct
+	return SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: format}
+}
+
+func BasicAuth() SecurityScheme {
+	//nolint:exhaustruct
+	return SecurityScheme{Type: "http", Scheme: "basic"}
+}
+
+// APIKeyAuth is a scheme for an api key passed in the header, query parameter or cookie called name.
+func APIKeyAuth(in string, name string) SecurityScheme {
+	//nolint:exhaustruct
+	return SecurityScheme{Type: "apiKey", Name: name, In: in}
+}
+
+func OAuth2Auth(flows OAuthFlows) SecurityScheme {
+	//nolint:exhaustruct
+	return SecurityScheme{Type: "oauth2", Flows: &flows}
+}
+
+// AddSecurityScheme documents a security scheme, which routes then require with WithSecurity.
+func (o *OpenAPI) AddSecurityScheme(name string, scheme SecurityScheme) {
+	components := o.components()
+	if components.SecuritySchemes == nil {
+		components.SecuritySchemes = orderedmap.New[string, SecurityScheme]()
+	}
+
+	components.SecuritySchemes.Set(name, scheme)
+}
+
+func (o *OpenAPI) hasSecurityScheme(name string) bool {
+	if o.document.Components == nil || o.document.Components.SecuritySchemes == nil {
+		return false
+	}
+
+	_, found := o.document.Components.SecuritySchemes.Get(name)
+
+	return found
+}
+
+// Security is a security requirement of a route, mapping the names of security schemes to the OAuth2 scopes they
+// need. A route that accepts any of several requirements is passed one for each, and the empty Security makes
+// authentication optional.
+type Security map[string][]string
+
+func (s Security) privatePathOption() {}
+
+// WithSecurity documents that a route requires the security scheme called name, with the given OAuth2 scopes.
+func WithSecurity(name string, scopes ...string) Security {
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	return Security{name: scopes}
+}
+
+// SetDefaultSecurity documents the routes added afterwards with requirements, such as the routes behind an auth
+// middleware applied with echo.Use or group.Use. Routes passing their own Security replace the defaults.
+func (o *OpenAPI) SetDefaultSecurity(requirements ...Security) {
+	o.defaultSecurity = requirements
+}
+
+type withAuth struct {
+	middleware echo.MiddlewareFunc
+	security   []Security
+}
+
+func (w withAuth) privatePathOption() {}
+
+// WithAuth puts a route behind AuthMiddleware and documents it with BearerSecurityScheme.
+func WithAuth(tokenVerifier TokenVerifier, development bool) withAuth {
+	return withAuth{
+		middleware: AuthMiddleware(tokenVerifier, development),
+		security:   []Security{WithSecurity(BearerSecurityScheme)},
+	}
+}
+
+// WithMaybeAuth puts a route behind MaybeAuthMiddleware and documents it with an optional BearerSecurityScheme.
+func WithMaybeAuth(tokenVerifier TokenVerifier, development bool) withAuth {
+	return withAuth{
+		middleware: MaybeAuthMiddleware(tokenVerifier, development),
+		security:   []Security{WithSecurity(BearerSecurityScheme), {}},
+	}
+}
+
+//nolint:gochecknoglobals
+var (
+	requireAuthPointer = reflect.ValueOf(authMiddleware{}.require).Pointer() //nolint:exhaustruct
+	allowAuthPointer   = reflect.ValueOf(authMiddleware{}.allow).Pointer()   //nolint:exhaustruct
+)
+
+// authSecurity returns the security requirements documenting middleware if it is made by AuthMiddleware or
+// MaybeAuthMiddleware. Their middlewares are method values, which share the code pointer of the method.
+func authSecurity(middleware echo.MiddlewareFunc) ([]Security, bool) {
+	switch reflect.ValueOf(middleware).Pointer() {
+	case requireAuthPointer:
+		return []Security{WithSecurity(BearerSecurityScheme)}, true
+	case allowAuthPointer:
+		return []Security{WithSecurity(BearerSecurityScheme), {}}, true
+	default:
+		return nil, false
+	}
+}
+
+var errUnknownSecurityScheme = errors.New("unknown security scheme")
+
+// checkSecurity returns an error if an operation requires a security scheme that is not added. BearerSecurityScheme
+// is added as a bearer scheme when it is required and missing.
+func (o *OpenAPI) checkSecurity() error {
+	for pair := o.document.Paths.Oldest(); pair != nil; pair = pair.Next() {
+		methods := make([]string, 0, len(pair.Value))
+		for method := range pair.Value {
+			methods = append(methods, method)
+		}
+
+		slices.Sort(methods)
+
+		for _, method := range methods {
+			for _, requirement := range pair.Value[method].Security {
+				for name := range requirement {
+					if name == BearerSecurityScheme && !o.hasSecurityScheme(name) {
+						o.AddSecurityScheme(BearerSecurityScheme, BearerAuth(""))
+					}
+
+					if !o.hasSecurityScheme(name) {
+						return fmt.Errorf("%s %s requires %s: %w", strings.ToUpper(method), pair.Key, name,
+							errUnknownSecurityScheme)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
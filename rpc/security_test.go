@@ -0,0 +1,114 @@
+package rpc_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DimmyJing/valise/rpc"
+	"github.com/DimmyJing/valise/vctx"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurity(t *testing.T) { //nolint:funlen
+	t.Parallel()
+
+	verifier := func(_ vctx.Context, token string) (string, error) {
+		return token, nil
+	}
+
+	ech := echo.New()
+	ech.HTTPErrorHandler = rpc.HTTPErrorHandler
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+	oapi.AddSecurityScheme("apiKey", rpc.APIKeyAuth("header", "X-API-Key"))
+	//nolint:exhaustruct
+	oapi.AddSecurityScheme("oauth", rpc.OAuth2Auth(rpc.OAuthFlows{
+		ClientCredentials: &rpc.OAuthFlow{TokenURL: "https://example.com/token", Scopes: map[string]string{
+			"items:read": "read items",
+		}},
+	}))
+
+	_, err := oapi.GET(ech, "/public", HandlerTest1)
+	require.NoError(t, err)
+	_, err = oapi.GET(ech, "/private", HandlerTest1, rpc.WithAuth(verifier, false))
+	require.NoError(t, err)
+	_, err = oapi.GET(ech, "/maybe", HandlerTest1, rpc.WithMaybeAuth(verifier, false))
+	require.NoError(t, err)
+	_, err = oapi.GET(ech, "/middleware", HandlerTest1, rpc.Middleware(rpc.AuthMiddleware(verifier, false)))
+	require.NoError(t, err)
+	_, err = oapi.GET(ech, "/maybe-middleware", HandlerTest1,
+		rpc.Middleware(rpc.MaybeAuthMiddleware(verifier, false)))
+	require.NoError(t, err)
+	_, err = oapi.GET(ech, "/logged", HandlerTest1, rpc.Middleware(rpc.LogMiddleware()))
+	require.NoError(t, err)
+	_, err = oapi.GET(ech, "/partner", HandlerTest1,
+		rpc.WithSecurity("apiKey"),
+		rpc.WithSecurity("oauth", "items:read"),
+	)
+	require.NoError(t, err)
+
+	// the auth of the group is not visible to the routes, so it is documented with the default security.
+	admin := ech.Group("/admin", rpc.AuthMiddleware(verifier, false))
+	oapi.SetDefaultSecurity(rpc.WithSecurity(rpc.BearerSecurityScheme))
+	_, err = oapi.GET(admin, "/stats", HandlerTest1)
+	require.NoError(t, err)
+	_, err = oapi.GET(admin, "/health", HandlerTest1, rpc.Security{})
+	require.NoError(t, err)
+	oapi.SetDefaultSecurity()
+
+	for _, path := range []string{"/private?name=a", "/middleware?name=a", "/admin/stats?name=a"} {
+		rec := httptest.NewRecorder()
+		ech.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, path)
+	}
+
+	require.NoError(t, oapi.Flush(ech))
+
+	doc, err := oapi.Document()
+	require.NoError(t, err)
+
+	var parsed struct {
+		Paths map[string]map[string]struct {
+			Security json.RawMessage `json:"security"`
+		} `json:"paths"`
+		Components struct {
+			SecuritySchemes json.RawMessage `json:"securitySchemes"`
+		} `json:"components"`
+	}
+
+	require.NoError(t, json.Unmarshal(doc, &parsed))
+
+	assert.Nil(t, parsed.Paths["/public"]["get"].Security)
+	assert.JSONEq(t, `[{"bearerAuth":[]}]`, string(parsed.Paths["/private"]["get"].Security))
+	assert.JSONEq(t, `[{"bearerAuth":[]},{}]`, string(parsed.Paths["/maybe"]["get"].Security))
+	assert.JSONEq(t, `[{"bearerAuth":[]}]`, string(parsed.Paths["/middleware"]["get"].Security))
+	assert.JSONEq(t, `[{"bearerAuth":[]},{}]`, string(parsed.Paths["/maybe-middleware"]["get"].Security))
+	assert.Nil(t, parsed.Paths["/logged"]["get"].Security)
+	assert.JSONEq(t, `[{"apiKey":[]},{"oauth":["items:read"]}]`, string(parsed.Paths["/partner"]["get"].Security))
+	assert.JSONEq(t, `[{"bearerAuth":[]}]`, string(parsed.Paths["/admin/stats"]["get"].Security))
+	assert.JSONEq(t, `[{}]`, string(parsed.Paths["/admin/health"]["get"].Security))
+	assert.JSONEq(t, `{
+		"apiKey": {"type": "apiKey", "name": "X-API-Key", "in": "header"},
+		"oauth": {"type": "oauth2", "flows": {"clientCredentials": {
+			"tokenUrl": "https://example.com/token", "scopes": {"items:read": "read items"}
+		}}},
+		"bearerAuth": {"type": "http", "scheme": "bearer"}
+	}`, string(parsed.Components.SecuritySchemes))
+}
+
+func TestSecurityUnknownScheme(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	_, err := oapi.GET(ech, "/partner", HandlerTest1, rpc.WithSecurity("apiKey"))
+	require.NoError(t, err)
+
+	err = oapi.Flush(ech)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "GET /partner requires apiKey")
+}
@@ -0,0 +1,267 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DimmyJing/valise/jsonschema"
+	"github.com/DimmyJing/valise/vctx"
+	"github.com/labstack/echo/v4"
+)
+
+const MIMETextEventStream = "text/event-stream"
+
+// Event is a server-sent event. Name, ID and Retry are optional.
+type Event[T any] struct {
+	Name  string
+	ID    string
+	Retry time.Duration
+	Data  T
+}
+
+// Stream sends typed server-sent events to the client of a stream handler.
+type Stream[T any] struct {
+	writer *streamWriter
+}
+
+type streamParam interface {
+	eventType() reflect.Type
+	setWriter(writer *streamWriter)
+}
+
+//nolint:gochecknoglobals
+var streamParamInterface = reflect.TypeOf((*streamParam)(nil)).Elem()
+
+func (s Stream[T]) eventType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (s *Stream[T]) setWriter(writer *streamWriter) {
+	s.writer = writer
+}
+
+// Send sends data as an unnamed event.
+func (s Stream[T]) Send(data T) error {
+	return s.SendEvent(Event[T]{Name: "", ID: "", Retry: 0, Data: data})
+}
+
+// SendEvent sends an event, returning ErrStreamClosed once the client has disconnected.
+func (s Stream[T]) SendEvent(event Event[T]) error {
+	if strings.ContainsAny(event.Name, "\r\n") || strings.ContainsAny(event.ID, "\r\n") {
+		return fmt.Errorf("event name and id must not contain newlines: %w", errInvalidEvent)
+	}
+
+	data, err := jsonschema.ValueToAny(reflect.ValueOf(event.Data))
+	if err != nil {
+		return fmt.Errorf("error converting event data: %w", err)
+	}
+
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("error marshaling event data: %w", err)
+	}
+
+	var builder strings.Builder
+
+	if event.Name != "" {
+		builder.WriteString("event: " + event.Name + "\n")
+	}
+
+	if event.ID != "" {
+		builder.WriteString("id: " + event.ID + "\n")
+	}
+
+	if event.Retry > 0 {
+		builder.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+
+	builder.WriteString("data: " + string(dataBytes) + "\n\n")
+
+	return s.writer.write(builder.String())
+}
+
+// LastEventID returns the Last-Event-ID sent by a reconnecting client, or an empty string.
+func (s Stream[T]) LastEventID() string {
+	return s.writer.lastEventID
+}
+
+var (
+	ErrStreamClosed = errors.New("stream closed")
+	errInvalidEvent = errors.New("invalid event")
+)
+
+type streamWriter struct {
+	mu          sync.Mutex
+	response    *echo.Response
+	ctx         vctx.Context
+	lastEventID string
+	committed   bool
+}
+
+func (w *streamWriter) write(data string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.ctx.Err() != nil {
+		return ErrStreamClosed
+	}
+
+	if !w.committed {
+		header := w.response.Header()
+		header.Set(echo.HeaderContentType, MIMETextEventStream)
+		header.Set(echo.HeaderCacheControl, "no-cache")
+		header.Set(echo.HeaderConnection, "keep-alive")
+		header.Set("X-Accel-Buffering", "no")
+		w.response.WriteHeader(http.StatusOK)
+		w.committed = true
+	}
+
+	if _, err := w.response.Write([]byte(data)); err != nil {
+		return fmt.Errorf("%w: %w", ErrStreamClosed, err)
+	}
+
+	w.response.Flush()
+
+	return nil
+}
+
+func (w *streamWriter) heartbeat(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.write(": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func isStreamHandler(handler any) (reflect.Type, reflect.Type, reflect.Type, bool) {
+	handlerFnType := reflect.TypeOf(handler)
+
+	if handlerFnType == nil || handlerFnType.Kind() != reflect.Func {
+		return nil, nil, nil, false
+	}
+
+	//nolint:mnd
+	if handlerFnType.NumIn() != 3 || handlerFnType.NumOut() != 1 {
+		return nil, nil, nil, false
+	}
+
+	if handlerFnType.Out(0) != reflect.TypeOf((*error)(nil)).Elem() {
+		return nil, nil, nil, false
+	}
+
+	if handlerFnType.In(0).Kind() != reflect.Struct {
+		return nil, nil, nil, false
+	}
+
+	if handlerFnType.In(1) != reflect.TypeOf(vctx.FromBackground()) {
+		return nil, nil, nil, false
+	}
+
+	streamType := handlerFnType.In(2)
+	if streamType.Kind() != reflect.Struct || !reflect.PointerTo(streamType).Implements(streamParamInterface) {
+		return nil, nil, nil, false
+	}
+
+	//nolint:forcetypeassert
+	eventType := reflect.Zero(streamType).Interface().(interface{ eventType() reflect.Type }).eventType()
+
+	return handlerFnType.In(0), eventType, streamType, true
+}
+
+func createStreamHandler(
+	handler any,
+	method string,
+	inputType reflect.Type,
+	streamType reflect.Type,
+	opts routeOptions,
+	preHandlerHook func(vctx.Context, any) vctx.Context,
+) (echo.HandlerFunc, error) {
+	hasBody := slices.Contains(hasBodyMethods, method)
+
+	inputFieldAttrsMap, err := getInputFieldAttrs(inputType, hasBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get input field attrs: %w", err)
+	}
+
+	handlerValue := reflect.ValueOf(handler)
+
+	return echo.HandlerFunc(func(echoCtx echo.Context) error {
+		ctx := FromEchoContext(echoCtx).ctx
+
+		inputValue, err := bindInput(inputFieldAttrsMap, hasBody, echoCtx, ctx, inputType, opts)
+		if err != nil {
+			return err
+		}
+
+		if preHandlerHook != nil {
+			ctx = preHandlerHook(ctx, inputValue.Interface())
+		}
+
+		//nolint:exhaustruct
+		writer := &streamWriter{
+			response:    echoCtx.Response(),
+			ctx:         ctx,
+			lastEventID: echoCtx.Request().Header.Get("Last-Event-ID"),
+		}
+
+		streamValue := reflect.New(streamType)
+		//nolint:forcetypeassert
+		streamValue.Interface().(streamParam).setWriter(writer)
+
+		done := make(chan struct{})
+
+		var heartbeatWait sync.WaitGroup
+
+		if opts.heartbeat > 0 {
+			heartbeatWait.Add(1)
+
+			go func() {
+				defer heartbeatWait.Done()
+				writer.heartbeat(opts.heartbeat, done)
+			}()
+		}
+
+		out := handlerValue.Call([]reflect.Value{inputValue, reflect.ValueOf(ctx), streamValue.Elem()})
+
+		close(done)
+		heartbeatWait.Wait()
+
+		handlerErr, _ := out[0].Interface().(error)
+
+		writer.mu.Lock()
+		committed := writer.committed
+		writer.mu.Unlock()
+
+		switch {
+		case handlerErr == nil && !committed:
+			// commit the headers of an empty stream.
+			_ = writer.write("")
+		case handlerErr == nil, errors.Is(handlerErr, ErrStreamClosed):
+		case committed:
+			// the status has already been written, so the error can only be logged.
+			_ = ctx.Capture(fmt.Errorf("stream handler failed: %w", handlerErr))
+		default:
+			return failHandler(ctx, handlerErr)
+		}
+
+		return nil
+	}), nil
+}
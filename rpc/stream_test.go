@@ -0,0 +1,107 @@
+package rpc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DimmyJing/valise/rpc"
+	"github.com/DimmyJing/valise/vctx"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testTickInput struct {
+	Count int `json:"count"`
+}
+
+type testTick struct {
+	Index int `json:"index"`
+}
+
+func HandlerTicks(inp testTickInput, ctx vctx.Context, stream rpc.Stream[testTick]) error {
+	start := 0
+	if lastID, err := strconv.Atoi(stream.LastEventID()); err == nil {
+		start = lastID + 1
+	}
+
+	for i := start; i < inp.Count; i++ {
+		err := stream.SendEvent(rpc.Event[testTick]{Name: "tick", ID: strconv.Itoa(i), Retry: 0, Data: testTick{Index: i}})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func HandlerSlowTicks(inp testTickInput, ctx vctx.Context, stream rpc.Stream[testTick]) error {
+	time.Sleep(50 * time.Millisecond)
+
+	return stream.Send(testTick{Index: inp.Count})
+}
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	ech.HTTPErrorHandler = rpc.HTTPErrorHandler
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	_, err := oapi.GET(ech, "/ticks", HandlerTicks)
+	require.NoError(t, err)
+
+	_, err = oapi.GET(ech, "/slow", HandlerSlowTicks, rpc.WithHeartbeat(10*time.Millisecond))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/ticks?count=2", nil)
+	rec := httptest.NewRecorder()
+	ech.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, rpc.MIMETextEventStream, rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t,
+		"event: tick\nid: 0\ndata: {\"index\":0}\n\nevent: tick\nid: 1\ndata: {\"index\":1}\n\n",
+		rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/ticks?count=3", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	rec = httptest.NewRecorder()
+	ech.ServeHTTP(rec, req)
+
+	assert.Equal(t, "event: tick\nid: 2\ndata: {\"index\":2}\n\n", rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/ticks?count=abc", nil)
+	rec = httptest.NewRecorder()
+	ech.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/slow?count=7", nil)
+	rec = httptest.NewRecorder()
+	ech.ServeHTTP(rec, req)
+
+	assert.Contains(t, rec.Body.String(), ": heartbeat\n\n")
+	assert.Contains(t, rec.Body.String(), "data: {\"index\":7}\n\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req = httptest.NewRequest(http.MethodGet, "/ticks?count=2", nil).WithContext(ctx)
+	rec = httptest.NewRecorder()
+	ech.ServeHTTP(rec, req)
+
+	assert.Empty(t, rec.Body.String())
+
+	require.NoError(t, oapi.Flush(ech))
+
+	dir := t.TempDir()
+	require.NoError(t, oapi.CodeGen(dir))
+
+	assert.Contains(t, readGenerated(t, dir, "swagger.json"), `"text/event-stream": {`)
+	assert.Contains(t, readGenerated(t, dir, "ticks.ts"), `responseContentType: "text/event-stream",`)
+}
@@ -0,0 +1,256 @@
+package rpc
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+type clientOperation struct {
+	fileName            string
+	stubName            string
+	method              string
+	path                string
+	requestContentType  string
+	responseContentType string
+}
+
+// clientFunctionName derives a unique function name from the method and path, e.g. putItemsById for PUT /items/{id}.
+func clientFunctionName(method string, path string) string {
+	name := strings.ToLower(method)
+
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+
+		prefix := ""
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segment = strings.Trim(segment, "{}")
+			prefix = "By"
+		}
+
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			name += prefix + strings.ToUpper(word[:1]) + word[1:]
+			prefix = ""
+		}
+	}
+
+	return name
+}
+
+const clientRuntime = `export type ClientOptions = {
+  baseUrl?: string;
+  fetch?: typeof fetch;
+  headers?: () => Record<string, string> | Promise<Record<string, string>>;
+  // cookie parameters are not part of the requests, as fetch cannot set the Cookie header. Browsers send them from
+  // the cookie jar, which needs "include" for other origins.
+  credentials?: RequestCredentials;
+};
+
+type Endpoint = {
+  method: string;
+  path: string;
+  requestContentType?: string;
+  responseContentType?: string;
+};
+
+type Values = Record<string, unknown>;
+
+export type RequestOf<T> = Pick<T, Extract<keyof T, "body" | "query" | "params" | "headers">>;
+
+export type ResponseOf<T> = T extends { responseContentType: "application/json" }
+  ? T extends { response: infer R } ? R : never
+  : T extends { responseContentType: "text/event-stream" }
+    ? Response
+    : T extends { responseContentType: string }
+      ? Blob
+      : null;
+
+export type ErrorOf<T> = T extends { errors: infer E extends { status: number, body: unknown } }
+  ? E
+  : { status: number, body: ErrorType | undefined };
+
+export class ApiError<
+  E extends { status: number, body: unknown } = { status: number, body: ErrorType | undefined },
+> extends Error {
+  readonly status: E["status"];
+  readonly body: E["body"];
+  readonly code: string | undefined;
+
+  constructor(error: E, statusText: string) {
+    const body = error.body as { code?: string, message?: string, detail?: string } | undefined;
+    super(body?.message || body?.detail || statusText);
+    this.name = "ApiError";
+    this.status = error.status;
+    this.body = error.body;
+    this.code = body?.code;
+  }
+}
+
+function eachValue(values: Values | undefined, fn: (key: string, value: unknown) => void) {
+  for (const [key, value] of Object.entries(values ?? {})) {
+    for (const elem of Array.isArray(value) ? value : [value]) {
+      if (elem !== undefined && elem !== null) {
+        fn(key, elem);
+      }
+    }
+  }
+}
+
+function encodeBody(endpoint: Endpoint, body: unknown, headers: Headers): BodyInit | undefined {
+  if (body === undefined) {
+    return undefined;
+  }
+
+  switch (endpoint.requestContentType) {
+    case "application/x-www-form-urlencoded": {
+      const form = new URLSearchParams();
+      eachValue(body as Values, (key, value) => form.append(key, String(value)));
+      return form;
+    }
+    case "multipart/form-data": {
+      const form = new FormData();
+      eachValue(body as Values, (key, value) => form.append(key, value instanceof Blob ? value : String(value)));
+      return form;
+    }
+    default:
+      headers.set("Content-Type", "application/json");
+      return JSON.stringify(body);
+  }
+}
+
+export function createClient(options: ClientOptions = {}) {
+  const baseUrl = options.baseUrl ?? "";
+  const doFetch = options.fetch ?? globalThis.fetch.bind(globalThis);
+
+  async function request<T>(endpoint: Endpoint, input: RequestOf<T>, init?: RequestInit): Promise<ResponseOf<T>> {
+    const values = input as unknown as { body?: unknown, query?: Values, params?: Values, headers?: Values };
+
+    let path = endpoint.path;
+    eachValue(values.params, (key, value) => {
+      path = path.replace("{" + key + "}", encodeURIComponent(String(value)));
+    });
+
+    const query = new URLSearchParams();
+    eachValue(values.query, (key, value) => query.append(key, String(value)));
+
+    const headers = new Headers(init?.headers);
+    for (const [key, value] of Object.entries(options.headers ? await options.headers() : {})) {
+      headers.set(key, value);
+    }
+
+    eachValue(values.headers, (key, value) => headers.append(key, String(value)));
+
+    const search = query.toString();
+    const response = await doFetch(baseUrl + path + (search ? "?" + search : ""), {
+      credentials: options.credentials,
+      ...init,
+      method: endpoint.method,
+      headers,
+      body: encodeBody(endpoint, values.body, headers),
+    });
+
+    if (!response.ok) {
+      const text = await response.text();
+      let body: unknown = undefined;
+      try {
+        body = text ? JSON.parse(text) : undefined;
+      } catch {
+        body = undefined;
+      }
+
+      throw new ApiError<ErrorOf<T>>({ status: response.status, body } as ErrorOf<T>, response.statusText);
+    }
+
+    if (response.status === 204 || !endpoint.responseContentType) {
+      return null as ResponseOf<T>;
+    }
+
+    switch (endpoint.responseContentType) {
+      case "application/json":
+        return (await response.json()) as ResponseOf<T>;
+      case "text/event-stream":
+        return response as ResponseOf<T>;
+      default:
+        return (await response.blob()) as ResponseOf<T>;
+    }
+  }
+
+  return {
+`
+
+func generateClient(operations []clientOperation, errorTypes []string) string {
+	var builder strings.Builder
+
+	imports := map[string][]string{}
+	importedFrom := map[string]string{}
+	localNames := make([]string, len(operations))
+
+	// stubs are only unique within their file, so those that another file already exports are imported under the name
+	// of their client function.
+	for idx, operation := range operations {
+		localNames[idx] = operation.stubName
+		imported := operation.stubName
+
+		if fileName, found := importedFrom[operation.stubName]; found && fileName != operation.fileName {
+			name := clientFunctionName(operation.method, operation.path)
+			localNames[idx] = strings.ToUpper(name[:1]) + name[1:]
+			imported = operation.stubName + " as " + localNames[idx]
+		} else {
+			importedFrom[operation.stubName] = operation.fileName
+		}
+
+		if !slices.Contains(imports[operation.fileName], imported) {
+			imports[operation.fileName] = append(imports[operation.fileName], imported)
+		}
+	}
+
+	fileNames := make([]string, 0, len(imports))
+	for fileName := range imports {
+		fileNames = append(fileNames, fileName)
+	}
+
+	sort.Strings(fileNames)
+
+	if len(errorTypes) > 0 {
+		builder.WriteString("import type { " + strings.Join(errorTypes, ", ") + " } from \"./common\"\n")
+	}
+
+	for _, fileName := range fileNames {
+		builder.WriteString("import type { " + strings.Join(imports[fileName], ", ") +
+			" } from \"./" + fileName + "\"\n")
+	}
+
+	// the error body of the routes that do not document their errors, one of those they were registered with.
+	errorType := "unknown"
+	if len(errorTypes) > 0 {
+		errorType = strings.Join(errorTypes, " | ")
+	}
+
+	builder.WriteString("\ntype ErrorType = " + errorType + "\n")
+
+	builder.WriteString("\n" + clientRuntime)
+
+	for idx, operation := range operations {
+		endpoint := "{ method: \"" + operation.method + "\", path: \"" + operation.path + "\""
+
+		if operation.requestContentType != "" {
+			endpoint += ", requestContentType: \"" + operation.requestContentType + "\""
+		}
+
+		if operation.responseContentType != "" {
+			endpoint += ", responseContentType: \"" + operation.responseContentType + "\""
+		}
+
+		endpoint += " }"
+
+		builder.WriteString("    " + clientFunctionName(operation.method, operation.path) +
+			": (input: RequestOf<" + localNames[idx] + ">, init?: RequestInit) =>\n" +
+			"      request<" + localNames[idx] + ">(" + endpoint + ", input, init),\n")
+	}
+
+	builder.WriteString("  };\n}\n\nexport type Client = ReturnType<typeof createClient>;\n")
+
+	return builder.String()
+}
@@ -0,0 +1,279 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/DimmyJing/valise/jsonschema"
+	"github.com/DimmyJing/valise/vctx"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+)
+
+// Conn is a WebSocket connection receiving In messages from the client and sending Out messages to it.
+type Conn[In any, Out any] struct {
+	conn *wsConn
+}
+
+type wsConn struct {
+	ws             *websocket.Conn
+	strictDecoding bool
+}
+
+type connParam interface {
+	messageTypes() (reflect.Type, reflect.Type)
+	setConn(conn *wsConn)
+}
+
+//nolint:gochecknoglobals
+var connParamInterface = reflect.TypeOf((*connParam)(nil)).Elem()
+
+func (c Conn[In, Out]) messageTypes() (reflect.Type, reflect.Type) {
+	return reflect.TypeOf((*In)(nil)).Elem(), reflect.TypeOf((*Out)(nil)).Elem()
+}
+
+func (c *Conn[In, Out]) setConn(conn *wsConn) {
+	c.conn = conn
+}
+
+// Receive waits for the next message from the client. It returns io.EOF once the client closes the connection,
+// and a *jsonschema.DecodeError or ValidationErrors for invalid messages.
+func (c Conn[In, Out]) Receive() (In, error) {
+	var msg In
+
+	var data string
+	if err := websocket.Message.Receive(c.conn.ws, &data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return msg, io.EOF
+		}
+
+		return msg, fmt.Errorf("error receiving message: %w", err)
+	}
+
+	var anyVal any
+	if err := json.Unmarshal([]byte(data), &anyVal); err != nil {
+		return msg, &jsonschema.DecodeError{Path: "", Err: fmt.Errorf("error decoding message json: %w", err)}
+	}
+
+	decodeOptions := []jsonschema.DecodeOption{}
+	if !c.conn.strictDecoding {
+		decodeOptions = append(decodeOptions, jsonschema.WithAllowUnknownFields())
+	}
+
+	msgValue := reflect.ValueOf(&msg).Elem()
+	if err := jsonschema.AnyToValue(anyVal, msgValue, decodeOptions...); err != nil {
+		return msg, fmt.Errorf("error converting message: %w", err)
+	}
+
+	if validationErrors := jsonschema.ValidateValue(msgValue); len(validationErrors) > 0 {
+		return msg, ValidationErrors(validationErrors)
+	}
+
+	return msg, nil
+}
+
+// Send sends a message to the client.
+func (c Conn[In, Out]) Send(msg Out) error {
+	anyVal, err := jsonschema.ValueToAny(reflect.ValueOf(msg))
+	if err != nil {
+		return fmt.Errorf("error converting message: %w", err)
+	}
+
+	data, err := json.Marshal(anyVal)
+	if err != nil {
+		return fmt.Errorf("error marshaling message: %w", err)
+	}
+
+	if err := websocket.Message.Send(c.conn.ws, string(data)); err != nil {
+		return fmt.Errorf("error sending message: %w", err)
+	}
+
+	return nil
+}
+
+// ValidationErrors is returned by Conn.Receive for messages that violate their validate tags.
+type ValidationErrors []jsonschema.ValidationError
+
+func (v ValidationErrors) Error() string {
+	if len(v) == 0 {
+		return "validation failed"
+	}
+
+	return fmt.Sprintf("validation failed: %s", v[0].Error())
+}
+
+func isWSHandler(handler any) (reflect.Type, reflect.Type, bool) {
+	handlerFnType := reflect.TypeOf(handler)
+
+	if handlerFnType == nil || handlerFnType.Kind() != reflect.Func {
+		return nil, nil, false
+	}
+
+	//nolint:mnd
+	if handlerFnType.NumIn() != 3 || handlerFnType.NumOut() != 1 {
+		return nil, nil, false
+	}
+
+	if handlerFnType.Out(0) != reflect.TypeOf((*error)(nil)).Elem() {
+		return nil, nil, false
+	}
+
+	if handlerFnType.In(0).Kind() != reflect.Struct {
+		return nil, nil, false
+	}
+
+	if handlerFnType.In(1) != reflect.TypeOf(vctx.FromBackground()) {
+		return nil, nil, false
+	}
+
+	connType := handlerFnType.In(2)
+	if connType.Kind() != reflect.Struct || !reflect.PointerTo(connType).Implements(connParamInterface) {
+		return nil, nil, false
+	}
+
+	return handlerFnType.In(0), connType, true
+}
+
+func connMessageTypes(connType reflect.Type) (reflect.Type, reflect.Type) {
+	//nolint:forcetypeassert
+	return reflect.Zero(connType).Interface().(interface {
+		messageTypes() (reflect.Type, reflect.Type)
+	}).messageTypes()
+}
+
+func createWSHandler(
+	handler any,
+	inputType reflect.Type,
+	connType reflect.Type,
+	opts routeOptions,
+	preHandlerHook func(vctx.Context, any) vctx.Context,
+) (echo.HandlerFunc, error) {
+	inputFieldAttrsMap, err := getInputFieldAttrs(inputType, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get input field attrs: %w", err)
+	}
+
+	handlerValue := reflect.ValueOf(handler)
+
+	return echo.HandlerFunc(func(echoCtx echo.Context) error {
+		ctx := FromEchoContext(echoCtx).ctx
+
+		inputValue, err := bindInput(inputFieldAttrsMap, false, echoCtx, ctx, inputType, opts)
+		if err != nil {
+			return err
+		}
+
+		if preHandlerHook != nil {
+			ctx = preHandlerHook(ctx, inputValue.Interface())
+		}
+
+		var handlerErr error
+
+		//nolint:exhaustruct
+		websocket.Server{
+			Handshake: checkWSOrigin(opts.origins),
+			Handler: func(ws *websocket.Conn) {
+				defer ws.Close()
+
+				connValue := reflect.New(connType)
+				//nolint:forcetypeassert
+				connValue.Interface().(connParam).setConn(&wsConn{ws: ws, strictDecoding: opts.strictDecoding})
+
+				out := handlerValue.Call([]reflect.Value{inputValue, reflect.ValueOf(ctx), connValue.Elem()})
+				handlerErr, _ = out[0].Interface().(error)
+			},
+		}.ServeHTTP(echoCtx.Response(), echoCtx.Request())
+
+		if handlerErr != nil && !errors.Is(handlerErr, io.EOF) {
+			// the connection has already been upgraded, so the error can only be logged.
+			_ = ctx.Capture(fmt.Errorf("websocket handler failed: %w", handlerErr))
+		}
+
+		return nil
+	}), nil
+}
+
+var errWSOrigin = errors.New("websocket origin not allowed")
+
+// checkWSOrigin rejects handshakes from pages of other origins than the request host and origins, as browsers send the
+// cookies of the user along, which cross-site pages could otherwise use. Clients that send no Origin are not browsers,
+// and are accepted.
+func checkWSOrigin(origins []string) func(*websocket.Config, *http.Request) error {
+	return func(config *websocket.Config, req *http.Request) error {
+		origin, err := websocket.Origin(config, req)
+		if err != nil {
+			return fmt.Errorf("invalid origin: %w", err)
+		}
+
+		config.Origin = origin
+
+		if origin == nil || origin.Host == req.Host || slices.Contains(origins, origin.Scheme+"://"+origin.Host) {
+			return nil
+		}
+
+		return fmt.Errorf("%w: %s", errWSOrigin, origin)
+	}
+}
+
+// WS registers a WebSocket handler of the form func(In, vctx.Context, rpc.Conn[ClientMessage, ServerMessage]) error.
+// In is bound from the path, query, headers and cookies of the handshake request. Handshakes from pages of other
+// origins are rejected with 403, unless they are allowed with WithOrigins.
+func (o *OpenAPI) WS(
+	ech EchoInterface,
+	path string,
+	handler any,
+	options ...PathOption,
+) (echo.HandlerFunc, error) {
+	if _, _, ok := isWSHandler(handler); !ok {
+		return nil, fmt.Errorf("handler is not a valid websocket handler: %w", errInvalidHandler)
+	}
+
+	return o.Add(ech, http.MethodGet, path, handler, options...)
+}
+
+func (o *OpenAPI) getWSPathItem(
+	input reflect.Type,
+	connType reflect.Type,
+	opts routeOptions,
+) (*openAPIOperation, error) {
+	clientType, serverType := connMessageTypes(connType)
+
+	clientSchema, err := o.reflector.AnyToSchema(clientType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert client message to schema: %w", err)
+	}
+
+	serverSchema, err := o.reflector.AnyToSchema(serverType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert server message to schema: %w", err)
+	}
+
+	parameters, err := o.reflector.ParametersToSchema(input, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert input to schema: %w", err)
+	}
+
+	return &openAPIOperation{
+		Tags:        opts.tags,
+		Description: opts.description,
+		Responses: map[string]openAPIResponse{
+			strconv.Itoa(http.StatusSwitchingProtocols): {
+				Description: http.StatusText(http.StatusSwitchingProtocols),
+				Headers:     nil,
+				Content:     nil,
+			},
+		},
+		Parameters:  parameters,
+		RequestBody: nil,
+		Security:    opts.security,
+		WebSocket:   &openAPIWebSocket{ClientMessage: clientSchema, ServerMessage: serverSchema},
+		inputType:   nil,
+		outputType:  nil,
+	}, nil
+}
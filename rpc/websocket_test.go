@@ -0,0 +1,175 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DimmyJing/valise/rpc"
+	"github.com/DimmyJing/valise/vctx"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+type testChatInput struct {
+	Room string `in:"path" json:"room"`
+}
+
+type testChatClientMessage struct {
+	Text string `json:"text" validate:"min=1"`
+}
+
+type testChatServerMessage struct {
+	Room  string `json:"room"`
+	Text  string `json:"text,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+func HandlerChat(
+	inp testChatInput,
+	ctx vctx.Context,
+	conn rpc.Conn[testChatClientMessage, testChatServerMessage],
+) error {
+	for {
+		msg, err := conn.Receive()
+
+		var validationErrors rpc.ValidationErrors
+
+		switch {
+		case errors.Is(err, io.EOF):
+			return nil
+		case errors.As(err, &validationErrors):
+			err = conn.Send(testChatServerMessage{Room: inp.Room, Text: "", Error: validationErrors.Error()})
+		case err != nil:
+			return err
+		default:
+			err = conn.Send(testChatServerMessage{Room: inp.Room, Text: strings.ToUpper(msg.Text), Error: ""})
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// wsHandshake opens a websocket connection with the given Origin, or none if it is empty, and returns the status.
+func wsHandshake(t *testing.T, url string, origin string) int {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	return res.StatusCode
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	ech.HTTPErrorHandler = rpc.HTTPErrorHandler
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	_, err := oapi.WS(ech, "/chat/:room", HandlerChat)
+	require.NoError(t, err)
+
+	_, err = oapi.WS(ech, "/app/:room", HandlerChat, rpc.WithOrigins("https://app.example.com"))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(ech)
+	defer server.Close()
+
+	// browsers send the cookies of the user along, so pages of other origins are rejected.
+	assert.Equal(t, http.StatusForbidden, wsHandshake(t, server.URL+"/chat/lobby", "https://evil.example.com"))
+	assert.Equal(t, http.StatusForbidden, wsHandshake(t, server.URL+"/chat/lobby", "null"))
+	assert.Equal(t, http.StatusSwitchingProtocols, wsHandshake(t, server.URL+"/chat/lobby", server.URL))
+	assert.Equal(t, http.StatusSwitchingProtocols, wsHandshake(t, server.URL+"/chat/lobby", ""))
+
+	assert.Equal(t, http.StatusSwitchingProtocols, wsHandshake(t, server.URL+"/app/lobby", "https://app.example.com"))
+	assert.Equal(t, http.StatusForbidden, wsHandshake(t, server.URL+"/app/lobby", "https://evil.example.com"))
+
+	_, err = websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/chat/lobby", "", "https://evil.example.com")
+	require.Error(t, err)
+}
+
+func TestWebSocket(t *testing.T) {
+	t.Parallel()
+
+	ech := echo.New()
+	ech.HTTPErrorHandler = rpc.HTTPErrorHandler
+	oapi := rpc.New("test title", "test description", "1.0.0", false, "", "")
+
+	requireToken := rpc.Middleware(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(echoCtx echo.Context) error {
+			if echoCtx.QueryParam("token") != "secret" {
+				return rpc.NewHTTPError(http.StatusUnauthorized)
+			}
+
+			return next(echoCtx)
+		}
+	})
+
+	_, err := oapi.WS(ech, "/chat/:room", HandlerChat, requireToken)
+	require.NoError(t, err)
+
+	_, err = oapi.WS(ech, "/invalid", HandlerTest1)
+	require.Error(t, err)
+
+	server := httptest.NewServer(ech)
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/chat/lobby"
+
+	_, err = websocket.Dial(wsURL, "", server.URL)
+	require.Error(t, err)
+
+	conn, err := websocket.Dial(wsURL+"?token=secret", "", server.URL)
+	require.NoError(t, err)
+
+	require.NoError(t, websocket.Message.Send(conn, `{"text":"hello"}`))
+
+	var reply string
+	require.NoError(t, websocket.Message.Receive(conn, &reply))
+	assert.JSONEq(t, `{"room":"lobby","text":"HELLO"}`, reply)
+
+	require.NoError(t, websocket.Message.Send(conn, `{"text":""}`))
+	require.NoError(t, websocket.Message.Receive(conn, &reply))
+	assert.JSONEq(t, `{"room":"lobby","error":"validation failed: /text: length must be at least 1"}`, reply)
+
+	require.NoError(t, conn.Close())
+
+	require.NoError(t, oapi.Flush(ech))
+
+	dir := t.TempDir()
+	require.NoError(t, oapi.CodeGen(dir))
+
+	doc := readGenerated(t, dir, "swagger.json")
+	assert.Contains(t, doc, `"x-websocket": {`)
+	assert.Contains(t, doc, `"101": {`)
+
+	stub := readGenerated(t, dir, "chat.ts")
+	assert.Contains(t, stub, "export type ChatClientMessage = testChatClientMessage")
+	assert.Contains(t, stub, "export type ChatServerMessage = testChatServerMessage")
+	assert.Contains(t, readGenerated(t, dir, "common.ts"), "export type testChatClientMessage = {")
+	assert.Contains(t, stub, "send: ChatClientMessage,")
+	assert.Contains(t, stub, "receive: ChatServerMessage,")
+	assert.Contains(t, stub, "websocket: true,")
+}